@@ -7,9 +7,12 @@ import (
 )
 
 var (
-	hostStatus types.HostStatus
-	alerts     = make(map[types.HostAlertID][]types.HostAlert)
-	mu         sync.RWMutex
+	hostStatus   types.HostStatus
+	capacityPlan types.CapacityPlan
+	atRisk       []types.ContractRisk
+	connectivity types.ConnectivityCheck
+	alerts       = make(map[types.HostAlertID][]types.HostAlert)
+	mu           sync.RWMutex
 )
 
 // GetHostStatus returns the last connectivity report
@@ -28,7 +31,57 @@ func SetHostStatus(s types.HostStatus) {
 	mu.Unlock()
 }
 
-//GetAlerts returns all active alerts
+// GetConnectivity returns the last connectivity check
+func GetConnectivity() (c types.ConnectivityCheck) {
+	mu.RLock()
+	c = connectivity
+	mu.RUnlock()
+
+	return
+}
+
+// SetConnectivity updates the host's last connectivity check
+func SetConnectivity(c types.ConnectivityCheck) {
+	mu.Lock()
+	connectivity = c
+	mu.Unlock()
+}
+
+// GetCapacityPlan returns the last capacity plan
+func GetCapacityPlan() (p types.CapacityPlan) {
+	mu.RLock()
+	p = capacityPlan
+	mu.RUnlock()
+
+	return
+}
+
+// SetCapacityPlan updates the host's last capacity plan
+func SetCapacityPlan(p types.CapacityPlan) {
+	mu.Lock()
+	capacityPlan = p
+	mu.Unlock()
+}
+
+// GetContractsAtRisk returns the contracts at risk of missing their storage
+// proof from the last sync
+func GetContractsAtRisk() (contracts []types.ContractRisk) {
+	mu.RLock()
+	contracts = append(contracts, atRisk...)
+	mu.RUnlock()
+
+	return
+}
+
+// SetContractsAtRisk updates the contracts at risk of missing their storage
+// proof
+func SetContractsAtRisk(contracts []types.ContractRisk) {
+	mu.Lock()
+	atRisk = contracts
+	mu.Unlock()
+}
+
+// GetAlerts returns all active alerts
 func GetAlerts() (active []types.HostAlert) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web"
+)
+
+// Status returns the host's current status and alerts
+func (c *Client) Status(ctx context.Context) (resp web.HostStatusResponse, err error) {
+	err = c.get(ctx, "/status", nil, &resp)
+	return
+}
+
+// Totals returns the host's totals for the day, month, and year of the date
+// and its lifetime totals. A zero date uses the current time.
+func (c *Client) Totals(ctx context.Context, date time.Time) (resp web.HostTotalResponse, err error) {
+	err = c.get(ctx, "/totals", buildQuery("date", date), &resp)
+	return
+}
+
+// Snapshots returns the host's daily snapshots for the 16 months around the
+// end date. A zero end uses the current time. Use SnapshotsRange to retrieve
+// the snapshots between two dates.
+func (c *Client) Snapshots(ctx context.Context, end time.Time) (resp web.HostSnapshotResponse, err error) {
+	err = c.get(ctx, "/snapshots", buildQuery("end", end), &resp)
+	return
+}
+
+// Forecast returns the host's projected revenue and storage for the number of
+// months. Zero uses the server's default.
+func (c *Client) Forecast(ctx context.Context, months int) (resp web.HostForecastResponse, err error) {
+	err = c.get(ctx, "/forecast", buildQuery("months", months), &resp)
+	return
+}
+
+// Capacity returns the projected date the host and its storage folders will
+// be full
+func (c *Client) Capacity(ctx context.Context) (resp web.HostCapacityResponse, err error) {
+	err = c.get(ctx, "/capacity", nil, &resp)
+	return
+}
+
+// ContractsAtRisk returns the contracts whose storage proof deadline is
+// approaching
+func (c *Client) ContractsAtRisk(ctx context.Context) (resp web.ContractsAtRiskResponse, err error) {
+	err = c.get(ctx, "/contracts/at-risk", nil, &resp)
+	return
+}
+
+// Collateral returns the host's collateral usage between start and end. Zero
+// timestamps use the server's defaults.
+func (c *Client) Collateral(ctx context.Context, start, end time.Time) (resp web.HostCollateralResponse, err error) {
+	err = c.get(ctx, "/collateral", buildQuery("start", start, "end", end), &resp)
+	return
+}
+
+// Metrics returns the host's hourly metrics between start and end. Zero
+// timestamps use the server's defaults.
+func (c *Client) Metrics(ctx context.Context, start, end time.Time) (resp web.HostMetricsResponse, err error) {
+	err = c.get(ctx, "/metrics", buildQuery("start", start, "end", end), &resp)
+	return
+}
+
+// Uptime returns the host's uptime and outages between start and end. Zero
+// timestamps use the server's defaults.
+func (c *Client) Uptime(ctx context.Context, start, end time.Time) (resp web.HostUptimeResponse, err error) {
+	err = c.get(ctx, "/uptime", buildQuery("start", start, "end", end), &resp)
+	return
+}
+
+// Bandwidth returns the host's bandwidth usage between start and end grouped
+// by hour, day, week, or month. Empty values use the server's defaults.
+func (c *Client) Bandwidth(ctx context.Context, granularity string, start, end time.Time) (resp web.HostBandwidthResponse, err error) {
+	err = c.get(ctx, "/bandwidth", buildQuery("granularity", granularity, "start", start, "end", end), &resp)
+	return
+}
+
+// Reports returns the generated daily and weekly reports
+func (c *Client) Reports(ctx context.Context) (resp web.HostReportsResponse, err error) {
+	err = c.get(ctx, "/reports", nil, &resp)
+	return
+}
+
+// Report returns the generated report with the id
+func (c *Client) Report(ctx context.Context, id string) (report types.HostReport, err error) {
+	buf, err := c.ReportContent(ctx, id, "json")
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(buf, &report); err != nil {
+		err = fmt.Errorf("decode response: %w", err)
+	}
+	return
+}
+
+// ReportContent returns the generated report with the id rendered as html,
+// json, or md. An empty format returns html.
+func (c *Client) ReportContent(ctx context.Context, id, format string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, "/reports/"+url.PathEscape(id), buildQuery("format", format), nil)
+}
+
+// Shares returns the public share tokens and the fields that can be shared
+func (c *Client) Shares(ctx context.Context) (resp web.SharesResponse, err error) {
+	err = c.get(ctx, "/shares", nil, &resp)
+	return
+}
+
+// CreateShare creates a public share token for the fields
+func (c *Client) CreateShare(ctx context.Context, fields []string) (types.ShareToken, error) {
+	var resp web.ShareResponse
+	if err := c.post(ctx, "/shares", web.ShareRequest{Fields: fields}, &resp); err != nil {
+		return types.ShareToken{}, err
+	}
+
+	return resp.Share, nil
+}
+
+// RevokeShare revokes the public share token
+func (c *Client) RevokeShare(ctx context.Context, token string) error {
+	return c.delete(ctx, "/shares/"+url.PathEscape(token))
+}
+
+// APIStats returns the API's usage metrics by endpoint
+func (c *Client) APIStats(ctx context.Context) (resp web.APIStatsResponse, err error) {
+	err = c.get(ctx, "/admin/stats", nil, &resp)
+	return
+}
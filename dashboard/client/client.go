@@ -0,0 +1,332 @@
+// Package client is a typed client for the dashboard's API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+const (
+	// unixPrefix the prefix of unix socket addresses
+	unixPrefix = "unix:"
+	// apiPrefix the path prefix of the API version the client supports
+	apiPrefix = "/api/v1"
+
+	defaultTimeout    = 30 * time.Second
+	defaultRetries    = 3
+	defaultRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 30 * time.Second
+)
+
+type (
+	// Options options when creating a client
+	Options struct {
+		// Address the dashboard's address. Either a URL, a host and port, or
+		// a unix socket path prefixed with "unix:". Defaults to
+		// "http://localhost:8884".
+		Address string
+		// BasePath the path prefix the dashboard is served under
+		BasePath string
+		// Username and Password if set, requests use HTTP basic auth.
+		// Used when the dashboard is behind an authenticating proxy.
+		Username string
+		Password string
+		// Token if set, requests are sent with a bearer token
+		Token string
+		// Retries the number of times a failed idempotent request is
+		// retried. Defaults to 3, a negative value disables retries.
+		Retries int
+		// RetryDelay the delay before the first retry, doubled after each
+		// attempt. Defaults to 500ms.
+		RetryDelay time.Duration
+		// HTTPClient the client used to send requests. Defaults to a client
+		// with a 30 second timeout.
+		HTTPClient *http.Client
+	}
+
+	// Client a client for the dashboard's API
+	Client struct {
+		opts    Options
+		baseURL string
+		client  *http.Client
+	}
+
+	// Error an error response returned by the API
+	Error struct {
+		StatusCode int
+		Code       router.ErrorCode
+		Message    string
+		RequestID  string
+	}
+)
+
+// Error implements error
+func (e *Error) Error() string {
+	if len(e.Code) == 0 {
+		return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("%s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if the error is a not found response from the API
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// retryable returns true if the request may succeed if it is sent again
+func (e *Error) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// New creates a new client
+func New(opts Options) *Client {
+	if len(opts.Address) == 0 {
+		opts.Address = "http://localhost:8884"
+	}
+
+	if opts.Retries == 0 {
+		opts.Retries = defaultRetries
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultRetryDelay
+	}
+
+	c := &Client{
+		opts:   opts,
+		client: opts.HTTPClient,
+	}
+
+	address := opts.Address
+	if strings.HasPrefix(address, unixPrefix) {
+		socket := strings.TrimPrefix(address, unixPrefix)
+		address = "http://unix"
+
+		if c.client == nil {
+			var dialer net.Dialer
+			c.client = &http.Client{
+				Timeout: defaultTimeout,
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						return dialer.DialContext(ctx, "unix", socket)
+					},
+				},
+			}
+		}
+	} else if !strings.Contains(address, "://") {
+		if strings.HasPrefix(address, ":") {
+			address = "localhost" + address
+		}
+		address = "http://" + address
+	}
+
+	if c.client == nil {
+		c.client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	c.baseURL = strings.TrimRight(address, "/") + router.NormalizeBasePath(opts.BasePath) + apiPrefix
+
+	return c
+}
+
+// decodeError returns the API error from a failed response
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(router.RequestIDHeader),
+	}
+
+	var body router.ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil || len(body.Message) == 0 {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+		return apiErr
+	}
+
+	apiErr.Code = body.Code
+	apiErr.Message = body.Message
+	if len(body.RequestID) != 0 {
+		apiErr.RequestID = body.RequestID
+	}
+
+	return apiErr
+}
+
+// retryDelay returns how long to wait before the next attempt. The server's
+// Retry-After header is used if it is set, the delay is capped at 30 seconds.
+func (c *Client) retryDelay(attempt int, resp *http.Response) time.Duration {
+	delay := time.Duration(float64(c.opts.RetryDelay) * math.Pow(2, float64(attempt)))
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
+
+// send sends a single request and returns the response body of a successful
+// request
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body []byte) (resp *http.Response, buf []byte, err error) {
+	u := c.baseURL + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if len(c.opts.Username) != 0 || len(c.opts.Password) != 0 {
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	} else if len(c.opts.Token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+c.opts.Token)
+	}
+
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, nil, decodeError(resp)
+	}
+
+	buf, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return resp, buf, nil
+}
+
+// do sends the request and returns the response body. GET requests that fail
+// with a network error, a server error, or a rate limit are retried.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body []byte) ([]byte, error) {
+	retries := 0
+	if method == http.MethodGet {
+		retries = c.opts.Retries
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, buf, err := c.send(ctx, method, path, query, body)
+		if err == nil {
+			return buf, nil
+		}
+
+		var apiErr *Error
+		if attempt >= retries || ctx.Err() != nil || (errors.As(err, &apiErr) && !apiErr.retryable()) {
+			return nil, err
+		}
+
+		t := time.NewTimer(c.retryDelay(attempt, resp))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// get sends a GET request and decodes the JSON response into v
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	buf, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// post sends a POST request with the JSON encoded request body and decodes
+// the JSON response into v
+func (c *Client) post(ctx context.Context, path string, req, v interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	buf, err := c.do(ctx, http.MethodPost, path, nil, body)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// delete sends a DELETE request
+func (c *Client) delete(ctx context.Context, path string) error {
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil)
+	return err
+}
+
+// buildQuery returns the query parameters from name value pairs. Timestamps
+// are encoded as unix seconds, zero values are omitted so the server's
+// default is used.
+func buildQuery(params ...interface{}) url.Values {
+	query := make(url.Values)
+
+	for i := 0; i+1 < len(params); i += 2 {
+		name := params[i].(string)
+
+		switch v := params[i+1].(type) {
+		case time.Time:
+			if !v.IsZero() {
+				query.Set(name, strconv.FormatInt(v.Unix(), 10))
+			}
+		case string:
+			if len(v) != 0 {
+				query.Set(name, v)
+			}
+		case int:
+			if v != 0 {
+				query.Set(name, strconv.Itoa(v))
+			}
+		}
+	}
+
+	return query
+}
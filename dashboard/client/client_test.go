@@ -0,0 +1,534 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/client"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+// seedDays the number of days of snapshots stored, long enough to span
+// several 16 month snapshot windows
+const seedDays = 3 * 365
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "client")
+	if err != nil {
+		panic(err)
+	}
+
+	code := func() int {
+		defer os.RemoveAll(dir)
+
+		if err := persist.InitializeDB(dir, nil); err != nil {
+			panic(err)
+		}
+		defer func() {
+			_ = persist.CloseDB()
+		}()
+
+		if err := seed(); err != nil {
+			panic(err)
+		}
+
+		return m.Run()
+	}()
+
+	os.Exit(code)
+}
+
+// seed stores a snapshot with a single new contract for every day and the
+// host's current status
+func seed() error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	snapshots := make([]types.HostSnapshot, 0, seedDays)
+	for i := 0; i < seedDays; i++ {
+		snapshots = append(snapshots, types.HostSnapshot{
+			ActiveContracts: 7,
+			NewContracts:    1,
+			Timestamp:       today.AddDate(0, 0, -i),
+		})
+	}
+
+	if err := persist.SaveHostSnapshots(snapshots...); err != nil {
+		return err
+	}
+
+	if err := persist.SaveHostMeta(types.HostMeta{
+		ActiveContracts: 7,
+		UsedStorage:     500,
+		TotalStorage:    1000,
+		Timestamp:       time.Now().UTC().Truncate(time.Hour),
+	}); err != nil {
+		return err
+	}
+
+	cache.SetHostStatus(types.HostStatus{Version: "1.5.6", AcceptingContracts: true})
+	cache.AddAlert("test", types.HostAlert{Type: "test", Text: "test alert", Severity: "warning"})
+
+	return nil
+}
+
+// newServer serves the dashboard's router. Requests to the API are counted by
+// path.
+func newServer(t *testing.T, opts router.APIOptions) (*httptest.Server, map[string]*int64) {
+	t.Helper()
+
+	if opts.RateLimit == 0 {
+		opts.RateLimit = 1000
+		opts.RateInterval = time.Second
+	}
+
+	h, err := web.NewRouter(opts).Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]*int64{
+		"/snapshots": new(int64),
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for path, n := range counts {
+			if strings.HasSuffix(r.URL.Path, path) {
+				atomic.AddInt64(n, 1)
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, counts
+}
+
+func TestStatus(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{})
+	c := client.New(client.Options{Address: srv.URL})
+
+	resp, err := c.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Status.ActiveContracts != 7 {
+		t.Fatalf("expected 7 active contracts, got %d", resp.Status.ActiveContracts)
+	} else if resp.Status.Version != "1.5.6" || !resp.Status.AcceptingContracts {
+		t.Fatalf("expected the cached status, got version %q accepting %t", resp.Status.Version, resp.Status.AcceptingContracts)
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{})
+	c := client.New(client.Options{Address: srv.URL})
+
+	resp, err := c.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(resp.Alerts))
+	} else if resp.Alerts[0].Text != "test alert" || resp.Alerts[0].Severity != "warning" {
+		t.Fatalf("unexpected alert %+v", resp.Alerts[0])
+	}
+}
+
+func TestTotals(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{})
+	c := client.New(client.Options{Address: srv.URL})
+
+	date := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -40)
+	resp, err := c.Totals(context.Background(), date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	daysInMonth := uint64(time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day())
+
+	if resp.Total.ActiveContracts != 7 {
+		t.Fatalf("expected 7 active contracts, got %d", resp.Total.ActiveContracts)
+	} else if resp.Month.NewContracts != daysInMonth {
+		t.Fatalf("expected %d new contracts in the month, got %d", daysInMonth, resp.Month.NewContracts)
+	} else if !resp.Month.Timestamp.Equal(date) {
+		t.Fatalf("expected timestamp %s, got %s", date, resp.Month.Timestamp)
+	}
+}
+
+func TestSnapshotsRange(t *testing.T) {
+	srv, counts := newServer(t, router.APIOptions{})
+	c := client.New(client.Options{Address: srv.URL})
+
+	to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	from := to.AddDate(-2, -6, 0)
+
+	snapshots, err := c.SnapshotsRange(context.Background(), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests := atomic.LoadInt64(counts["/snapshots"]); requests < 3 {
+		t.Fatalf("expected the range to span several windows, got %d requests", requests)
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	if len(snapshots) != days {
+		t.Fatalf("expected %d snapshots, got %d", days, len(snapshots))
+	}
+
+	for i, snapshot := range snapshots {
+		expected := from.AddDate(0, 0, i)
+		if !snapshot.Timestamp.Equal(expected) {
+			t.Fatalf("snapshot %d: expected timestamp %s, got %s", i, expected, snapshot.Timestamp)
+		} else if snapshot.NewContracts != 1 {
+			t.Fatalf("snapshot %d: expected 1 new contract, got %d", i, snapshot.NewContracts)
+		}
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{BasePath: "/sia"})
+
+	if _, err := client.New(client.Options{Address: srv.URL, BasePath: "/sia"}).Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.New(client.Options{Address: srv.URL}).Status(context.Background())
+	if !client.IsNotFound(err) {
+		t.Fatalf("expected not found without the base path, got %v", err)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{})
+	c := client.New(client.Options{Address: srv.URL})
+
+	_, err := c.Report(context.Background(), "missing")
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	} else if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != router.ErrCodeNotFound {
+		t.Fatalf("expected not found, got %d %s", apiErr.StatusCode, apiErr.Code)
+	} else if apiErr.Message != "report not found" {
+		t.Fatalf("unexpected message %q", apiErr.Message)
+	} else if len(apiErr.RequestID) == 0 {
+		t.Fatal("expected a request ID")
+	}
+
+	_, err = c.Forecast(context.Background(), 100)
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	} else if apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != router.ErrCodeInvalidParameter {
+		t.Fatalf("expected invalid parameter, got %d %s", apiErr.StatusCode, apiErr.Code)
+	}
+}
+
+// failingServer returns a server that fails the first n requests with the
+// status and counts the requests it receives
+func failingServer(t *testing.T, n int64, status int, retryAfter string) (*httptest.Server, *int64) {
+	t.Helper()
+
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) <= n {
+			if len(retryAfter) != 0 {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"type":"success"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		minDelay   time.Duration
+	}{
+		{"server error", http.StatusServiceUnavailable, "", 0},
+		{"rate limited", http.StatusTooManyRequests, "1", time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := failingServer(t, 2, tt.status, tt.retryAfter)
+			c := client.New(client.Options{Address: srv.URL, RetryDelay: time.Millisecond})
+
+			start := time.Now()
+			if _, err := c.Status(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if n := atomic.LoadInt64(requests); n != 3 {
+				t.Fatalf("expected 3 requests, got %d", n)
+			} else if elapsed := time.Since(start); elapsed < 2*tt.minDelay {
+				t.Fatalf("expected Retry-After to delay the retries, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryLimit(t *testing.T) {
+	srv, requests := failingServer(t, 10, http.StatusInternalServerError, "")
+	c := client.New(client.Options{Address: srv.URL, Retries: 2, RetryDelay: time.Millisecond})
+
+	_, err := c.Status(context.Background())
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected an internal error, got %v", err)
+	} else if n := atomic.LoadInt64(requests); n != 3 {
+		t.Fatalf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestNoRetryNonIdempotent(t *testing.T) {
+	srv, requests := failingServer(t, 10, http.StatusServiceUnavailable, "")
+	c := client.New(client.Options{Address: srv.URL, RetryDelay: time.Millisecond})
+
+	if _, err := c.CreateShare(context.Background(), []string{"status"}); err == nil {
+		t.Fatal("expected an error")
+	} else if n := atomic.LoadInt64(requests); n != 1 {
+		t.Fatalf("expected POST to be sent once, got %d requests", n)
+	}
+
+	if err := c.RevokeShare(context.Background(), "token"); err == nil {
+		t.Fatal("expected an error")
+	} else if n := atomic.LoadInt64(requests); n != 2 {
+		t.Fatalf("expected DELETE to be sent once, got %d requests", n-1)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	var header atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header.Store(r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"type":"success"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name     string
+		opts     client.Options
+		expected string
+	}{
+		{"none", client.Options{}, ""},
+		{"basic", client.Options{Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz"},
+		{"bearer", client.Options{Token: "secret"}, "Bearer secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Address = srv.URL
+			if _, err := client.New(tt.opts).Status(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if auth := header.Load().(string); auth != tt.expected {
+				t.Fatalf("expected Authorization %q, got %q", tt.expected, auth)
+			}
+		})
+	}
+}
+
+// originTransport sets the Origin header of every request like a browser
+type originTransport string
+
+func (o originTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Origin", string(o))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// forwardedTransport sets the X-Forwarded-For header of every request like a
+// reverse proxy
+type forwardedTransport string
+
+func (f forwardedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Forwarded-For", string(f))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestSecure(t *testing.T) {
+	tests := []struct {
+		name string
+		// opts returns the client's options for the server's url
+		opts     func(url string) client.Options
+		expected router.ErrorCode
+	}{
+		{"no token", func(string) client.Options { return client.Options{} }, router.ErrCodeUnauthorized},
+		{"invalid token", func(string) client.Options { return client.Options{Token: "invalid"} }, router.ErrCodeUnauthorized},
+		{"cross origin", func(string) client.Options {
+			return client.Options{HTTPClient: &http.Client{Transport: originTransport("http://example.com")}}
+		}, router.ErrCodeForbidden},
+		{"bearer", func(string) client.Options { return client.Options{Token: "secret"} }, ""},
+		{"basic", func(string) client.Options { return client.Options{Username: "user", Password: "secret"} }, ""},
+		{"same origin", func(url string) client.Options {
+			return client.Options{HTTPClient: &http.Client{Transport: originTransport(url)}}
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// failed requests count towards the rate limit of creating
+			// shares, each case uses a new server
+			srv, _ := newServer(t, router.APIOptions{
+				AuthToken: "secret",
+				CORS: router.CORSOptions{
+					Enabled: true,
+					Origins: []string{"*"},
+					Methods: []string{"*"},
+				},
+			})
+
+			opts := tt.opts(srv.URL)
+			opts.Address = srv.URL
+			c := client.New(opts)
+
+			share, err := c.CreateShare(context.Background(), []string{"version"})
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				} else if err := c.RevokeShare(context.Background(), share.Token); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || apiErr.Code != tt.expected {
+				t.Fatalf("expected %s, got %v", tt.expected, err)
+			}
+
+			_, err = c.APIStats(context.Background())
+			if !errors.As(err, &apiErr) || apiErr.Code != tt.expected {
+				t.Fatalf("expected %s from API stats, got %v", tt.expected, err)
+			}
+
+			// endpoints that are not secure are still public
+			if _, err := c.Status(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSecureLocal(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{})
+
+	tests := []struct {
+		name     string
+		opts     client.Options
+		expected router.ErrorCode
+	}{
+		{"local", client.Options{}, ""},
+		{"forwarded local", client.Options{HTTPClient: &http.Client{Transport: forwardedTransport("127.0.0.1")}}, ""},
+		{"forwarded remote", client.Options{HTTPClient: &http.Client{Transport: forwardedTransport("203.0.113.10")}}, router.ErrCodeForbidden},
+		{"cross origin", client.Options{HTTPClient: &http.Client{Transport: originTransport("http://example.com")}}, router.ErrCodeForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Address = srv.URL
+			c := client.New(tt.opts)
+
+			_, err := c.APIStats(context.Background())
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || apiErr.Code != tt.expected {
+				t.Fatalf("expected %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestSecureRateLimit(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{
+		AuthToken:    "secret",
+		RateLimit:    3,
+		RateInterval: time.Minute,
+	})
+
+	c := client.New(client.Options{
+		Address: srv.URL,
+		Token:   "invalid",
+		Retries: -1,
+	})
+
+	for i := 0; i < 4; i++ {
+		expected := router.ErrCodeUnauthorized
+		if i == 3 {
+			expected = router.ErrCodeRateLimited
+		}
+
+		var apiErr *client.Error
+		if _, err := c.APIStats(context.Background()); !errors.As(err, &apiErr) || apiErr.Code != expected {
+			t.Fatalf("request %d: expected %s, got %v", i, expected, err)
+		}
+	}
+}
+
+func TestSecureCORS(t *testing.T) {
+	srv, _ := newServer(t, router.APIOptions{
+		CORS: router.CORSOptions{
+			Enabled: true,
+			Origins: []string{"*"},
+			Methods: []string{"*"},
+		},
+	})
+
+	tests := []struct {
+		path    string
+		method  string
+		allowed bool
+	}{
+		{"/api/v1/status", http.MethodGet, true},
+		{"/api/v1/shares", http.MethodPost, false},
+		{"/api/shares/token", http.MethodDelete, false},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", "http://example.com")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if allowed := len(resp.Header.Get("Access-Control-Allow-Origin")) != 0; allowed != tt.allowed {
+			t.Fatalf("%s %s: expected CORS allowed %t, got %t", tt.method, tt.path, tt.allowed, allowed)
+		}
+	}
+}
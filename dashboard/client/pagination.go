@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// defaultMetricsWindow the time range requested per page by MetricsRange
+const defaultMetricsWindow = 7 * 24 * time.Hour
+
+// SnapshotsRange returns the host's daily snapshots between from and to,
+// sorted by timestamp. The snapshots endpoint returns a fixed 16 month window
+// so ranges longer than a single window are retrieved with multiple requests.
+// A zero from returns every snapshot, a zero to uses the current time.
+func (c *Client) SnapshotsRange(ctx context.Context, from, to time.Time) ([]types.HostSnapshot, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("to must be after from")
+	}
+
+	seen := make(map[int64]bool)
+	var snapshots []types.HostSnapshot
+
+	for end := to; ; {
+		resp, err := c.Snapshots(ctx, end)
+		if err != nil {
+			return nil, err
+		}
+
+		if from.IsZero() && len(resp.Snapshots) == 0 {
+			break
+		}
+
+		// the first day of a window also includes the last hour of the
+		// previous day. When an earlier window is requested it returns the
+		// day instead.
+		more := resp.Start.After(from) && resp.Start.Before(end)
+
+		for _, snapshot := range resp.Snapshots {
+			ts := snapshot.Timestamp.Unix()
+			if seen[ts] || snapshot.Timestamp.Before(from) || snapshot.Timestamp.After(to) {
+				continue
+			} else if more && snapshot.Timestamp.Equal(resp.Start) {
+				continue
+			}
+
+			seen[ts] = true
+			snapshots = append(snapshots, snapshot)
+		}
+
+		if !more {
+			break
+		}
+
+		// the next window ends the day before the current window starts
+		end = resp.Start.AddDate(0, 0, -1)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+
+	return snapshots, nil
+}
+
+// MetricsRange retrieves the host's hourly metrics between start and end one
+// window at a time and calls fn with each page in order. Returning an error
+// from fn stops the iteration. A zero window requests a week at a time.
+func (c *Client) MetricsRange(ctx context.Context, start, end time.Time, window time.Duration, fn func([]types.HostMetrics) error) error {
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	if end.Before(start) {
+		return errors.New("end must be after start")
+	}
+
+	if window <= 0 {
+		window = defaultMetricsWindow
+	}
+
+	var last time.Time
+	for pageStart := start; pageStart.Before(end); pageStart = pageStart.Add(window) {
+		pageEnd := pageStart.Add(window)
+		if pageEnd.After(end) {
+			pageEnd = end
+		}
+
+		resp, err := c.Metrics(ctx, pageStart, pageEnd)
+		if err != nil {
+			return err
+		}
+
+		// windows share their boundaries, skip metrics already returned in
+		// the previous page
+		metrics := resp.Metrics[:0]
+		for _, m := range resp.Metrics {
+			if !last.IsZero() && !m.Timestamp.After(last) {
+				continue
+			}
+
+			metrics = append(metrics, m)
+		}
+
+		if len(metrics) == 0 {
+			continue
+		}
+
+		last = metrics[len(metrics)-1].Timestamp
+		if err := fn(metrics); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
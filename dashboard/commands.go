@@ -0,0 +1,539 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/client"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+
+	dateFormat = "2006-01-02"
+
+	// commandTimeout the maximum time a command waits for the dashboard's
+	// API or the database lock
+	commandTimeout = 10 * time.Second
+)
+
+type (
+	// commandOptions the flags shared by every command
+	commandOptions struct {
+		apiAddr  string
+		basePath string
+		dataPath string
+		useDB    bool
+		format   string
+
+		// apiSet true if --api-addr was set, the database is not used as a
+		// fallback
+		apiSet bool
+	}
+
+	// hostSource the source of the data printed by a command, either the
+	// running dashboard's API or the database
+	hostSource interface {
+		Status(ctx context.Context) (types.HostStatus, error)
+		Alerts(ctx context.Context) ([]types.HostAlert, error)
+		Totals(ctx context.Context, date time.Time) (web.HostTotalResponse, error)
+		Snapshots(ctx context.Context, from, to time.Time) ([]types.HostSnapshot, error)
+	}
+
+	apiSource struct {
+		c *client.Client
+	}
+
+	dbSource struct{}
+)
+
+// commands the subcommands that are run instead of the dashboard
+var commands = map[string]func(args []string) error{
+	"status":    statusCommand,
+	"totals":    totalsCommand,
+	"alerts":    alertsCommand,
+	"snapshots": snapshotsCommand,
+	"service":   serviceCommand,
+}
+
+// errAlertsUnavailable alerts are generated while syncing and are not stored
+var errAlertsUnavailable = errors.New("alerts are only available from a running dashboard")
+
+// isCommand returns true if the arguments start with a subcommand
+func isCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	_, exists := commands[args[0]]
+	return exists
+}
+
+// runCommand runs the subcommand and returns the process's exit code
+func runCommand(args []string) int {
+	if err := commands[args[0]](args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func (s apiSource) Status(ctx context.Context) (types.HostStatus, error) {
+	resp, err := s.c.Status(ctx)
+	return resp.Status, err
+}
+
+func (s apiSource) Alerts(ctx context.Context) ([]types.HostAlert, error) {
+	resp, err := s.c.Status(ctx)
+	return resp.Alerts, err
+}
+
+func (s apiSource) Totals(ctx context.Context, date time.Time) (web.HostTotalResponse, error) {
+	return s.c.Totals(ctx, date)
+}
+
+func (s apiSource) Snapshots(ctx context.Context, from, to time.Time) ([]types.HostSnapshot, error) {
+	return s.c.SnapshotsRange(ctx, from, to)
+}
+
+// Status returns the host's status from the last stored metadata and
+// connectivity check. Values only reported by Sia while the dashboard is
+// running are not included.
+func (dbSource) Status(ctx context.Context) (types.HostStatus, error) {
+	meta, err := persist.GetLastMetadata()
+	if err != nil {
+		return types.HostStatus{}, fmt.Errorf("get metadata: %w", err)
+	}
+
+	usage, err := persist.GetClosestMeta(time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		return types.HostStatus{}, fmt.Errorf("get past usage: %w", err)
+	}
+
+	check, err := persist.GetLastConnectivityCheck()
+	if err != nil {
+		return types.HostStatus{}, fmt.Errorf("get connectivity: %w", err)
+	}
+
+	status := types.HostStatus{
+		HostMeta:           meta,
+		Online:             check.Online(),
+		AcceptingContracts: check.AcceptingContracts,
+		StorageDelta:       int64(meta.UsedStorage) - int64(usage.UsedStorage),
+		DaysUntilFull:      -1,
+	}
+
+	status.UploadBandwidth -= usage.UploadBandwidth
+	status.DownloadBandwidth -= usage.DownloadBandwidth
+
+	return status, nil
+}
+
+func (dbSource) Alerts(ctx context.Context) ([]types.HostAlert, error) {
+	return nil, errAlertsUnavailable
+}
+
+func (dbSource) Totals(ctx context.Context, date time.Time) (web.HostTotalResponse, error) {
+	return web.GetHostTotals(date)
+}
+
+func (dbSource) Snapshots(ctx context.Context, from, to time.Time) ([]types.HostSnapshot, error) {
+	daily, err := persist.GetDailySnapshots(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []types.HostSnapshot
+	for _, snapshot := range daily {
+		if snapshot.Timestamp.Before(from) || snapshot.Timestamp.After(to) {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// newCommandFlags returns a flag set with the flags shared by every command
+func newCommandFlags(name, usage string, opts *commandOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: dashboard %s [flags]\n\n%s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&opts.apiAddr, "api-addr", "localhost:8884", "the address of the running dashboard's API, unix sockets are prefixed with \"unix:\"")
+	fs.StringVar(&opts.basePath, "base-path", "", "the path prefix the dashboard is served under")
+	fs.StringVar(&opts.dataPath, "data-path", "data", "the data path containing hoststats.db, read when the dashboard is not running")
+	fs.BoolVar(&opts.useDB, "db", false, "reads hoststats.db directly instead of querying the running dashboard")
+	fs.StringVar(&opts.format, "format", formatTable, "the output format: table or json")
+
+	return fs
+}
+
+// parseCommandFlags parses the command's flags and validates the shared
+// flags
+func parseCommandFlags(fs *flag.FlagSet, opts *commandOptions, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "api-addr" {
+			opts.apiSet = true
+		}
+	})
+
+	switch opts.format {
+	case formatTable, formatJSON:
+	default:
+		return fmt.Errorf("unknown format %q, must be table or json", opts.format)
+	}
+
+	return nil
+}
+
+// withSource calls fn with the running dashboard's API. If the dashboard
+// cannot be reached, or --db is set, the database is opened read-only instead.
+func withSource(opts commandOptions, fn func(ctx context.Context, src hostSource) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	if !opts.useDB {
+		err := fn(ctx, apiSource{
+			c: client.New(client.Options{
+				Address:  opts.apiAddr,
+				BasePath: opts.basePath,
+				Retries:  -1,
+			}),
+		})
+
+		// only connection errors fall back to the database, errors returned
+		// by the API are reported
+		var apiErr *client.Error
+		if err == nil || opts.apiSet || errors.As(err, &apiErr) {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(os.Stderr, "unable to reach the dashboard at %s, reading the database\n", opts.apiAddr)
+	}
+
+	// a running dashboard holds the database lock, fail quickly instead of
+	// waiting for the full timeout
+	if err := persist.OpenReadOnly(opts.dataPath, 2*time.Second, nil); errors.Is(err, bolt.ErrTimeout) {
+		return fmt.Errorf("%w: the database is locked by a running dashboard, use --api-addr to query it", err)
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		_ = persist.CloseDB()
+	}()
+
+	return fn(ctx, dbSource{})
+}
+
+// parseDate parses a YYYY-MM-DD date as midnight UTC
+func parseDate(name, value string) (time.Time, error) {
+	t, err := time.ParseInLocation(dateFormat, value, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q, must be YYYY-MM-DD", name, value)
+	}
+
+	return t, nil
+}
+
+// today returns midnight UTC of the current day
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1000
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.2f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatBigNumber(b types.BigNumber) string {
+	precision, _ := siatypes.SiacoinPrecision.Float64()
+
+	return fmt.Sprintf("%.2f SC", b.Float64()/precision)
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func statusCommand(args []string) error {
+	var opts commandOptions
+
+	fs := newCommandFlags("status", "Prints the host's current status.", &opts)
+	if err := parseCommandFlags(fs, &opts, args); err != nil {
+		return err
+	}
+
+	return withSource(opts, func(ctx context.Context, src hostSource) error {
+		status, err := src.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		if opts.format == formatJSON {
+			return writeJSON(os.Stdout, status)
+		}
+
+		var usedPct float64
+		if status.TotalStorage != 0 {
+			usedPct = float64(status.UsedStorage) / float64(status.TotalStorage) * 100
+		}
+
+		tw := newTable(os.Stdout)
+		_, _ = fmt.Fprintf(tw, "Online:\t%s\n", formatBool(status.Online))
+		_, _ = fmt.Fprintf(tw, "Accepting Contracts:\t%s\n", formatBool(status.AcceptingContracts))
+		if len(status.Version) != 0 {
+			_, _ = fmt.Fprintf(tw, "Sia Version:\t%s\n", status.Version)
+			_, _ = fmt.Fprintf(tw, "Wallet Unlocked:\t%s\n", formatBool(status.WalletUnlocked))
+		}
+		_, _ = fmt.Fprintf(tw, "Storage:\t%s / %s (%.2f%%)\n", formatBytes(status.UsedStorage), formatBytes(status.TotalStorage), usedPct)
+		if status.DaysUntilFull >= 0 {
+			_, _ = fmt.Fprintf(tw, "Days Until Full:\t%.0f\n", status.DaysUntilFull)
+		}
+		_, _ = fmt.Fprintf(tw, "Active Contracts:\t%d\n", status.ActiveContracts)
+		_, _ = fmt.Fprintf(tw, "Successful Contracts:\t%d\n", status.SuccessfulContracts)
+		_, _ = fmt.Fprintf(tw, "Failed Contracts:\t%d\n", status.FailedContracts)
+		_, _ = fmt.Fprintf(tw, "Earned Revenue:\t%s\n", formatBigNumber(status.EarnedRevenue))
+		_, _ = fmt.Fprintf(tw, "Potential Revenue:\t%s\n", status.PotentialRevenue.HumanString())
+		_, _ = fmt.Fprintf(tw, "Locked Collateral:\t%s\n", status.LockedCollateral.HumanString())
+		_, _ = fmt.Fprintf(tw, "Burnt Collateral:\t%s\n", status.BurntCollateral.HumanString())
+		_, _ = fmt.Fprintf(tw, "Updated:\t%s\n", status.Timestamp.Local().Format(time.RFC1123))
+
+		return tw.Flush()
+	})
+}
+
+func alertsCommand(args []string) error {
+	var opts commandOptions
+
+	fs := newCommandFlags("alerts", "Prints the host's active alerts. Requires a running dashboard.", &opts)
+	if err := parseCommandFlags(fs, &opts, args); err != nil {
+		return err
+	}
+
+	return withSource(opts, func(ctx context.Context, src hostSource) error {
+		alerts, err := src.Alerts(ctx)
+		if err != nil {
+			return err
+		}
+
+		if opts.format == formatJSON {
+			if alerts == nil {
+				alerts = []types.HostAlert{}
+			}
+
+			return writeJSON(os.Stdout, alerts)
+		}
+
+		if len(alerts) == 0 {
+			_, err := fmt.Println("No alerts")
+			return err
+		}
+
+		sort.SliceStable(alerts, func(i, j int) bool {
+			return alerts[i].Severity > alerts[j].Severity
+		})
+
+		tw := newTable(os.Stdout)
+		_, _ = fmt.Fprintln(tw, "SEVERITY\tTYPE\tALERT")
+		for _, alert := range alerts {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", alert.Severity, alert.Type, alert.Text)
+		}
+
+		return tw.Flush()
+	})
+}
+
+func totalsCommand(args []string) error {
+	var opts commandOptions
+	var date string
+	var day, month, year, total bool
+
+	fs := newCommandFlags("totals", "Prints the host's totals for the day, month, and year of the date and its lifetime totals.", &opts)
+	fs.StringVar(&date, "date", "", "the date to print totals for as YYYY-MM-DD. Defaults to today")
+	fs.BoolVar(&day, "day", false, "only print the day's totals")
+	fs.BoolVar(&month, "month", false, "only print the month's totals")
+	fs.BoolVar(&year, "year", false, "only print the year's totals")
+	fs.BoolVar(&total, "total", false, "only print the lifetime totals")
+	if err := parseCommandFlags(fs, &opts, args); err != nil {
+		return err
+	}
+
+	timestamp := today()
+	if len(date) != 0 {
+		var err error
+		if timestamp, err = parseDate("date", date); err != nil {
+			return err
+		}
+	}
+
+	if !day && !month && !year && !total {
+		day, month, year, total = true, true, true, true
+	}
+
+	return withSource(opts, func(ctx context.Context, src hostSource) error {
+		resp, err := src.Totals(ctx, timestamp)
+		if err != nil {
+			return err
+		}
+
+		type row struct {
+			key, period string
+			snapshot    types.HostSnapshot
+		}
+
+		var rows []row
+		if day {
+			rows = append(rows, row{"day", timestamp.Format(dateFormat), resp.Day})
+		}
+		if month {
+			rows = append(rows, row{"month", timestamp.Format("Jan 2006"), resp.Month})
+		}
+		if year {
+			rows = append(rows, row{"year", timestamp.Format("2006"), resp.Year})
+		}
+		if total {
+			rows = append(rows, row{"total", "Total", resp.Total})
+		}
+
+		if opts.format == formatJSON {
+			out := make(map[string]types.HostSnapshot)
+			for _, r := range rows {
+				out[r.key] = r.snapshot
+			}
+
+			return writeJSON(os.Stdout, out)
+		}
+
+		tw := newTable(os.Stdout)
+		_, _ = fmt.Fprintln(tw, "PERIOD\tNEW\tSUCCESSFUL\tFAILED\tEARNED\tPOTENTIAL\tBURNT")
+		for _, r := range rows {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\n", r.period, r.snapshot.NewContracts,
+				r.snapshot.SuccessfulContracts, r.snapshot.FailedContracts, formatBigNumber(r.snapshot.EarnedRevenue),
+				r.snapshot.PotentialRevenue.HumanString(), r.snapshot.BurntCollateral.HumanString())
+		}
+
+		return tw.Flush()
+	})
+}
+
+func snapshotsCommand(args []string) error {
+	var opts commandOptions
+	var fromStr, toStr string
+
+	fs := newCommandFlags("snapshots", "Prints the host's daily snapshots between two dates.", &opts)
+	fs.StringVar(&fromStr, "from", "", "the first day to print as YYYY-MM-DD. Defaults to 30 days before --to")
+	fs.StringVar(&toStr, "to", "", "the last day to print as YYYY-MM-DD. Defaults to today")
+	if err := parseCommandFlags(fs, &opts, args); err != nil {
+		return err
+	}
+
+	to := today()
+	if len(toStr) != 0 {
+		var err error
+		if to, err = parseDate("to", toStr); err != nil {
+			return err
+		}
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if len(fromStr) != 0 {
+		var err error
+		if from, err = parseDate("from", fromStr); err != nil {
+			return err
+		}
+	}
+
+	if to.Before(from) {
+		return errors.New("--to must be after --from")
+	}
+
+	return withSource(opts, func(ctx context.Context, src hostSource) error {
+		snapshots, err := src.Snapshots(ctx, from, to)
+		if err != nil {
+			return err
+		}
+
+		if opts.format == formatJSON {
+			if snapshots == nil {
+				snapshots = []types.HostSnapshot{}
+			}
+
+			return writeJSON(os.Stdout, snapshots)
+		}
+
+		tw := newTable(os.Stdout)
+		_, _ = fmt.Fprintln(tw, "DATE\tACTIVE\tNEW\tEXPIRED\tSUCCESSFUL\tFAILED\tEARNED\tPOTENTIAL\tBURNT")
+		for _, snapshot := range snapshots {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n", snapshot.Timestamp.UTC().Format(dateFormat),
+				snapshot.ActiveContracts, snapshot.NewContracts, snapshot.ExpiredContracts, snapshot.SuccessfulContracts,
+				snapshot.FailedContracts, formatBigNumber(snapshot.EarnedRevenue), snapshot.PotentialRevenue.HumanString(),
+				snapshot.BurntCollateral.HumanString())
+		}
+
+		return tw.Flush()
+	})
+}
+
+// commandNames returns the sorted names of the subcommands
+func commandNames() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
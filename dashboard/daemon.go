@@ -4,19 +4,22 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/siacentral/sia-host-dashboard/dashboard/build"
 	"github.com/siacentral/sia-host-dashboard/dashboard/cmd"
+	"github.com/siacentral/sia-host-dashboard/dashboard/logging"
 	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/report"
+	"github.com/siacentral/sia-host-dashboard/dashboard/service"
 	"github.com/siacentral/sia-host-dashboard/dashboard/sync"
 	"github.com/siacentral/sia-host-dashboard/dashboard/web"
 	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
@@ -27,9 +30,28 @@ var (
 	listenAddr  string
 	siaAddr     string
 	disableCors bool
+	apiToken    string
 	logStdOut   bool
 	skipBrowser bool
-	logFile     *os.File
+	rebuildSnap bool
+	fullDays    int
+	connCheck   string
+	webhookURL  string
+	enableTLS   bool
+	tlsCert     string
+	tlsKey      string
+	tlsRedirect string
+	proxies     string
+	socketMode  string
+	basePath    string
+	logLevel    string
+	logFormat   string
+	logMaxSize  int64
+	logMaxAge   int
+	logFile     *logging.RotatingFile
+	logger      *logging.Logger
+	accessPath  string
+	accessLog   *logging.RotatingFile
 )
 
 func writeLine(format string, args ...interface{}) {
@@ -37,45 +59,122 @@ func writeLine(format string, args ...interface{}) {
 		_, _ = os.Stdout.WriteString(fmt.Sprintf(format, args...) + "\n")
 	}
 
-	log.Printf(format, args...)
+	logger.Info(fmt.Sprintf(format, args...))
 }
 
-func init() {
-	var err error
+// logRotateOptions returns the rotation options for the log files
+func logRotateOptions() logging.RotateOptions {
+	return logging.RotateOptions{
+		MaxSize: logMaxSize << 20,
+		MaxAge:  time.Duration(logMaxAge) * 24 * time.Hour,
+	}
+}
+
+// initLogger creates the logger and redirects the standard library's logger
+// to it. Output is written to data/log.log unless --std-out is set.
+func initLogger() error {
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+
+	format, err := logging.ParseFormat(logFormat)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if !logStdOut {
+		logFile, err = logging.OpenRotatingFile(filepath.Join(dataPath, "log.log"), logRotateOptions())
+		if err != nil {
+			return err
+		}
+
+		w = logFile
+	}
+
+	logger = logging.New(w, level, format)
+
+	log.SetFlags(0)
+	log.SetOutput(logger.Writer(logging.LevelError))
 
+	return nil
+}
+
+func init() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
+	// subcommands parse their own flags and do not start the dashboard
+	if isCommand(os.Args[1:]) {
+		return
+	}
+
 	flag.StringVar(&dataPath, "data-path", "data", "the data path to use")
-	flag.StringVar(&listenAddr, "listen-addr", ":8884", "the address to listen on, defaults to :8884")
+	flag.StringVar(&listenAddr, "listen-addr", ":8884", "comma separated list of addresses to listen on, unix sockets are prefixed with \"unix:\". Defaults to :8884")
+	flag.StringVar(&basePath, "base-path", "", "the path prefix to serve the dashboard under, for use behind a reverse proxy")
+	flag.StringVar(&socketMode, "socket-mode", "0660", "the file permissions of unix sockets")
 	flag.StringVar(&siaAddr, "sia-api-addr", os.Getenv("SIA_API_ADDR"), "the url used to connect to Sia. Defaults to \"localhost:9980\"")
 	flag.BoolVar(&disableCors, "disable-cors", false, "disables cross-origin requests, prevents cross-origin browser requests to the API")
+	flag.StringVar(&apiToken, "api-token", os.Getenv("DASHBOARD_API_TOKEN"), "the token required to manage shares and view API stats from outside the dashboard's web interface. Without a token they are only available locally. Defaults to $DASHBOARD_API_TOKEN")
 	flag.BoolVar(&logStdOut, "std-out", false, "sends output to stdout instead of the log file")
+	flag.StringVar(&logLevel, "log-level", "info", "the minimum level of log entries to write: debug, info, warn, or error")
+	flag.StringVar(&logFormat, "log-format", "logfmt", "the format of log entries: logfmt or json")
+	flag.Int64Var(&logMaxSize, "log-max-size", 10, "the size in megabytes the log file can grow to before it is rotated, 0 disables size based rotation")
+	flag.IntVar(&logMaxAge, "log-max-age", 30, "the number of days to keep rotated log files, 0 keeps them forever")
+	flag.StringVar(&accessPath, "access-log", "", "writes every request to the file in the combined log format")
 	flag.BoolVar(&skipBrowser, "skip-browser", false, "skips opening the browser")
+	flag.IntVar(&fullDays, "full-alert-days", 14, "alerts when storage is projected to be full within the number of days, 0 disables the alert")
+	flag.StringVar(&connCheck, "connectivity-check", sync.ConnectivityRemote, "how the host's connectivity is checked: local, remote, or both")
+	flag.StringVar(&webhookURL, "report-webhook", "", "posts the daily and weekly reports as JSON to the url")
+	flag.BoolVar(&enableTLS, "tls", false, "serves the dashboard over HTTPS, a self-signed certificate is generated if --tls-cert and --tls-key are not set")
+	flag.StringVar(&tlsCert, "tls-cert", "", "the TLS certificate file, enables HTTPS")
+	flag.StringVar(&tlsKey, "tls-key", "", "the TLS key file, enables HTTPS")
+	flag.StringVar(&tlsRedirect, "tls-redirect-addr", "", "the address to listen on for HTTP requests to redirect to HTTPS")
+	flag.StringVar(&proxies, "trusted-proxies", "", "comma separated list of proxy addresses or CIDRs allowed to set the client address with forwarding headers")
+	flag.BoolVar(&rebuildSnap, "rebuild-snapshots", false, "checks the stored snapshots against the stored contracts, rebuilds them, and exits")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: dashboard [flags]\n       dashboard <command> [flags]\n\nCommands: %s\n\nFlags:\n", commandNames())
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	if len(siaAddr) == 0 {
 		siaAddr = "localhost:9980"
 	}
 
+	if len(listenAddresses()) == 0 {
+		log.Fatalf("at least one listen address is required")
+	}
+
+	switch connCheck {
+	case sync.ConnectivityLocal, sync.ConnectivityRemote, sync.ConnectivityBoth:
+	default:
+		log.Fatalf("unknown connectivity check %q, must be local, remote, or both", connCheck)
+	}
+
 	if err := os.MkdirAll(dataPath, 0750); err != nil && !os.IsExist(err) {
 		log.Fatalf("error creating directory: %s", err)
 	}
 
-	if err := persist.InitializeDB(dataPath); err != nil {
-		log.Fatalf("error initializing database: %s", err)
+	if err := initLogger(); err != nil {
+		log.Fatalf("error initializing log: %s", err)
 	}
 
-	if logStdOut {
-		return
+	if err := persist.InitializeDB(dataPath, logger.With("module", "persist")); err != nil {
+		log.Fatalf("error initializing database: %s", err)
 	}
 
-	logFile, err = os.OpenFile(filepath.Join(dataPath, "log.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if (len(tlsCert) == 0) != (len(tlsKey) == 0) {
+		log.Fatalf("--tls-cert and --tls-key must be set together")
+	} else if len(tlsCert) != 0 {
+		enableTLS = true
+	} else if enableTLS {
+		tlsCert, tlsKey = filepath.Join(dataPath, "tls.crt"), filepath.Join(dataPath, "tls.key")
 
-	if err != nil {
-		log.Fatalf("error opening log: %s", err)
+		if err := router.EnsureSelfSignedCertificate(tlsCert, tlsKey); err != nil {
+			log.Fatalf("error generating certificate: %s", err)
+		}
 	}
-
-	log.SetOutput(logFile)
 }
 
 func openbrowser(url string) {
@@ -93,78 +192,197 @@ func openbrowser(url string) {
 	}
 
 	if err != nil {
-		log.Printf("warn: unable to open browser: %s", err)
+		logger.Warn("unable to open browser", "error", err)
 	}
 }
 
+// listenAddresses returns the addresses to listen on, TCP addresses are
+// ordered first
+func listenAddresses() (addresses []string) {
+	var sockets []string
+
+	for _, addr := range strings.Split(listenAddr, ",") {
+		addr = strings.TrimSpace(addr)
+
+		if len(addr) == 0 {
+			continue
+		} else if strings.HasPrefix(addr, "unix:") {
+			sockets = append(sockets, addr)
+		} else {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return append(addresses, sockets...)
+}
+
 func startAPI() {
+	trustedProxies, err := router.ParseTrustedProxies(strings.Split(proxies, ","))
+	if err != nil {
+		writeLine("Error parsing trusted proxies: %s", err)
+		os.Exit(1)
+	}
+
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil {
+		writeLine("Error parsing socket mode: %s", err)
+		os.Exit(1)
+	}
+
+	var accessWriter io.Writer
+	if len(accessPath) != 0 {
+		accessLog, err = logging.OpenRotatingFile(accessPath, logRotateOptions())
+		if err != nil {
+			writeLine("Error opening access log: %s", err)
+			os.Exit(1)
+		}
+
+		accessWriter = accessLog
+	}
+
 	if err := web.Start(router.APIOptions{
-		ListenAddress: listenAddr,
+		ListenAddresses: listenAddresses(),
+		SocketMode:      os.FileMode(mode),
+		BasePath:        basePath,
 		CORS: router.CORSOptions{
 			Enabled: !disableCors,
 			Origins: []string{"*"},
 			Methods: []string{"*"},
 		},
-		RateInterval: time.Second,
-		RateLimit:    10,
+		RateInterval:   time.Second,
+		RateLimit:      10,
+		TrustedProxies: trustedProxies,
+		AuthToken:      apiToken,
+		Log:            logger.With("module", "api"),
+		AccessLog:      accessWriter,
+		TLS: router.TLSOptions{
+			CertFile:        tlsCert,
+			KeyFile:         tlsKey,
+			RedirectAddress: tlsRedirect,
+		},
 	}); err != nil {
 		writeLine("Error starting API: %s", err)
 		os.Exit(1)
 	}
 }
 
+func rebuildSnapshots() {
+	writeLine("Rebuilding snapshots...")
+
+	mismatched, err := sync.RebuildSnapshots()
+	if err != nil {
+		writeLine("Error rebuilding snapshots: %s", err)
+	} else {
+		writeLine("Snapshots rebuilt, %d hours were inconsistent", mismatched)
+	}
+
+	if err := persist.CloseDB(); err != nil {
+		logger.Error("unable to close db", "error", err)
+	}
+}
+
 func main() {
 	var openAddr string
 
+	if isCommand(os.Args[1:]) {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	if rebuildSnap {
+		rebuildSnapshots()
+		return
+	}
+
 	cmd.StartedInExplorer()
 
+	stop, started := listenForStop()
+
 	writeLine("Starting Host Dashboard %s", build.Version())
 	writeLine("Revision: %s Build Time: %s", build.Revision(), build.Time().Format(time.RFC1123))
 	writeLine("Syncing Sia Data...")
 
 	syncStart := time.Now()
-	if err := sync.Start(siaAddr); err != nil {
+	if err := sync.Start(sync.Options{
+		SiaAddress:        siaAddr,
+		FullAlertDays:     fullDays,
+		ConnectivityCheck: connCheck,
+		Log:               logger.With("module", "sync"),
+	}); err != nil {
 		log.Fatalf("error syncing data: %s", err)
 	}
 
 	writeLine("Data synced in %s", time.Since(syncStart))
 
+	var senders []report.Sender
+	if len(webhookURL) != 0 {
+		senders = append(senders, report.WebhookSender{URL: webhookURL})
+	}
+
+	if err := report.Start(report.Options{
+		DataPath: dataPath,
+		Senders:  senders,
+		Log:      logger.With("module", "report"),
+	}); err != nil {
+		log.Fatalf("error starting reports: %s", err)
+	}
+
 	go startAPI()
 
-	if strings.Index(listenAddr, ":") == 0 {
-		openAddr = fmt.Sprintf("http://localhost%s", listenAddr)
+	scheme := "http"
+	if enableTLS {
+		scheme = "https"
+	}
+
+	addr := listenAddresses()[0]
+	if strings.HasPrefix(addr, "unix:") {
+		openAddr = addr
+	} else if strings.Index(addr, ":") == 0 {
+		openAddr = fmt.Sprintf("%s://localhost%s", scheme, addr)
 	} else {
-		openAddr = fmt.Sprintf("http://%s", listenAddr)
+		openAddr = fmt.Sprintf("%s://%s", scheme, addr)
 	}
 
 	writeLine("Host Dashboard Ready at: %s", openAddr)
 
-	if !skipBrowser {
-		openbrowser(openAddr)
+	if !strings.HasPrefix(openAddr, "unix:") {
+		openAddr += router.NormalizeBasePath(basePath) + "/"
 	}
 
-	sigChan := make(chan os.Signal, 1)
-
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	if !skipBrowser && !strings.HasPrefix(openAddr, "unix:") {
+		openbrowser(openAddr)
+	}
 
-	<-sigChan
+	close(started)
 
-	writeLine("Shutting down")
+	waitForStop(stop)
+	shutdown()
+}
 
+// shutdown stops the API, closes the database and logs, and reports the
+// dashboard as stopped to the service manager
+func shutdown() {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancelFunc()
 
 	if err := web.Shutdown(ctx); err != nil {
-		log.Println("unable to shutdown web:", err)
+		logger.Error("unable to shutdown web", "error", err)
 	}
 
 	if err := persist.CloseDB(); err != nil {
-		log.Println("unable to close db:", err)
+		logger.Error("unable to close db", "error", err)
+	}
+
+	if accessLog != nil {
+		if err := accessLog.Close(); err != nil {
+			logger.Error("unable to close access log", "error", err)
+		}
 	}
 
 	if logFile != nil {
 		if err := logFile.Close(); err != nil {
-			log.Println("unable to close log:", err)
+			_, _ = fmt.Fprintf(os.Stderr, "unable to close log: %s\n", err)
 		}
 	}
+
+	service.Exit()
 }
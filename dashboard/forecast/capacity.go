@@ -0,0 +1,78 @@
+package forecast
+
+import (
+	"sort"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// estimateCapacity fits the growth of used storage and projects when it will
+// reach capacity. x is the number of days relative to now.
+func estimateCapacity(xs, ys []float64, capacity, used uint64, now time.Time) types.CapacityEstimate {
+	estimate := types.CapacityEstimate{
+		Capacity:      capacity,
+		UsedStorage:   used,
+		DaysUntilFull: -1,
+	}
+
+	trend := FitLinear(xs, ys)
+	estimate.GrowthPerDay = trend.Slope
+
+	switch {
+	case used >= capacity && capacity > 0:
+		estimate.DaysUntilFull = 0
+		estimate.FullTimestamp = now
+	case trend.Slope > 0:
+		estimate.DaysUntilFull = float64(capacity-used) / trend.Slope
+		estimate.FullTimestamp = now.Add(time.Duration(estimate.DaysUntilFull * float64(24*time.Hour)))
+	}
+
+	return estimate
+}
+
+// PlanCapacity fits the growth of the host's used storage and each storage
+// folder's used storage over the metadata history and projects how many days
+// until they are full. Capacity and usage are taken from the last metadata.
+func PlanCapacity(metadata []types.HostMeta, now time.Time) (plan types.CapacityPlan) {
+	var xs, ys []float64
+	var last types.HostMeta
+
+	folderXs := make(map[string][]float64)
+	folderYs := make(map[string][]float64)
+
+	plan.Timestamp = now
+	plan.Host.DaysUntilFull = -1
+
+	if len(metadata) == 0 {
+		return
+	}
+
+	for _, meta := range metadata {
+		x := meta.Timestamp.Sub(now).Hours() / 24
+
+		xs = append(xs, x)
+		ys = append(ys, float64(meta.UsedStorage))
+
+		for _, folder := range meta.Folders {
+			folderXs[folder.Path] = append(folderXs[folder.Path], x)
+			folderYs[folder.Path] = append(folderYs[folder.Path], float64(folder.UsedStorage))
+		}
+
+		last = meta
+	}
+
+	plan.Host = estimateCapacity(xs, ys, last.TotalStorage, last.UsedStorage, now)
+
+	for _, folder := range last.Folders {
+		estimate := estimateCapacity(folderXs[folder.Path], folderYs[folder.Path], folder.Capacity, folder.UsedStorage, now)
+		estimate.Path = folder.Path
+		plan.Folders = append(plan.Folders, estimate)
+	}
+
+	sort.Slice(plan.Folders, func(i, j int) bool {
+		return plan.Folders[i].Path < plan.Folders[j].Path
+	})
+
+	return
+}
@@ -0,0 +1,84 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// dailyMetadata returns a sample per day ending at now with the host's used
+// storage. The storage folder uses half of the host's storage and capacity.
+func dailyMetadata(now time.Time, capacity uint64, used ...uint64) (metadata []types.HostMeta) {
+	for i, v := range used {
+		metadata = append(metadata, types.HostMeta{
+			Timestamp:    now.AddDate(0, 0, i-len(used)+1),
+			UsedStorage:  v,
+			TotalStorage: capacity,
+			Folders: []types.StorageFolder{
+				{Path: "/storage", UsedStorage: v / 2, Capacity: capacity / 2},
+			},
+		})
+	}
+
+	return
+}
+
+func TestPlanCapacity(t *testing.T) {
+	now := time.Date(2026, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		metadata []types.HostMeta
+		growth   float64
+		days     float64
+	}{
+		{"no samples", nil, 0, -1},
+		{"one sample", dailyMetadata(now, 1000, 100), 0, -1},
+		{"flat", dailyMetadata(now, 1000, 100, 100, 100), 0, -1},
+		{"growing", dailyMetadata(now, 1000, 100, 200, 300), 100, 7},
+		{"shrinking", dailyMetadata(now, 1000, 300, 200, 100), -100, -1},
+		{"full", dailyMetadata(now, 1000, 800, 900, 1000), 100, 0},
+		{"no capacity", dailyMetadata(now, 0, 0, 0), 0, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanCapacity(tt.metadata, now)
+
+			if !plan.Timestamp.Equal(now) {
+				t.Fatalf("expected timestamp %s, got %s", now, plan.Timestamp)
+			}
+
+			estimates := []types.CapacityEstimate{plan.Host}
+			if len(tt.metadata) != 0 {
+				if len(plan.Folders) != 1 {
+					t.Fatalf("expected 1 folder, got %d", len(plan.Folders))
+				}
+				estimates = append(estimates, plan.Folders[0])
+			}
+
+			for i, estimate := range estimates {
+				// the folder grows half as fast towards half the capacity
+				growth := tt.growth
+				if i > 0 {
+					growth /= 2
+				}
+
+				if !approxEqual(estimate.GrowthPerDay, growth) {
+					t.Fatalf("expected growth %g, got %g", growth, estimate.GrowthPerDay)
+				} else if !approxEqual(estimate.DaysUntilFull, tt.days) {
+					t.Fatalf("expected %g days until full, got %g", tt.days, estimate.DaysUntilFull)
+				}
+
+				if tt.days < 0 {
+					if !estimate.FullTimestamp.IsZero() {
+						t.Fatalf("expected no full timestamp, got %s", estimate.FullTimestamp)
+					}
+				} else if expected := now.Add(time.Duration(tt.days * float64(24*time.Hour))); !estimate.FullTimestamp.Equal(expected) {
+					t.Fatalf("expected full at %s, got %s", expected, estimate.FullTimestamp)
+				}
+			}
+		})
+	}
+}
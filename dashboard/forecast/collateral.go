@@ -0,0 +1,58 @@
+package forecast
+
+import (
+	"sort"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+func dayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CollateralTimeline projects when the collateral locked in unresolved
+// contracts will unlock, grouped by the day each contract expires. Contracts
+// already past expiration are expected to unlock today.
+func CollateralTimeline(contracts []types.HostContract, now time.Time) (timeline []types.CollateralUnlock) {
+	var locked siatypes.Currency
+
+	today := dayStart(now)
+	days := make(map[time.Time]types.CollateralUnlock)
+
+	for _, contract := range contracts {
+		if contract.Status != types.ContractStatusUnresolved {
+			continue
+		}
+
+		day := dayStart(contract.ExpirationTimestamp)
+		if day.Before(today) {
+			day = today
+		}
+
+		unlock := days[day]
+		unlock.Timestamp = day
+		unlock.Contracts++
+		unlock.Unlocked = unlock.Unlocked.Add(contract.LockedCollateral)
+		days[day] = unlock
+
+		locked = locked.Add(contract.LockedCollateral)
+	}
+
+	for _, unlock := range days {
+		timeline = append(timeline, unlock)
+	}
+
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Timestamp.Before(timeline[j].Timestamp)
+	})
+
+	for i := range timeline {
+		locked = locked.Sub(timeline[i].Unlocked)
+		timeline[i].RemainingLocked = locked
+	}
+
+	return
+}
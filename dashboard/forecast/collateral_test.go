@@ -0,0 +1,75 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+// lockedContract returns a contract with the status expiring at the timestamp
+// with the collateral locked
+func lockedContract(status string, expiration time.Time, collateral uint64) types.HostContract {
+	return types.HostContract{
+		Status:              status,
+		ExpirationTimestamp: expiration,
+		LockedCollateral:    siatypes.NewCurrency64(collateral),
+	}
+}
+
+func TestCollateralTimeline(t *testing.T) {
+	now := time.Date(2026, 5, 15, 12, 0, 0, 0, time.UTC)
+	today := dayStart(now)
+	unresolved := types.ContractStatusUnresolved
+
+	tests := []struct {
+		name      string
+		contracts []types.HostContract
+		// expected day offset, contracts, unlocked, and remaining locked
+		// collateral of each unlock
+		unlocks [][4]uint64
+	}{
+		{"no contracts", nil, nil},
+		{"resolved", []types.HostContract{
+			lockedContract(types.ContractStatusSucceeded, now.AddDate(0, 0, 1), 100),
+			lockedContract(types.ContractStatusFailed, now.AddDate(0, 0, 2), 100),
+		}, nil},
+		{"zero collateral", []types.HostContract{
+			lockedContract(unresolved, now.AddDate(0, 0, 1), 0),
+		}, [][4]uint64{{1, 1, 0, 0}}},
+		{"past expiration", []types.HostContract{
+			lockedContract(unresolved, now.AddDate(0, 0, -3), 100),
+			lockedContract(unresolved, now.Add(time.Hour), 50),
+		}, [][4]uint64{{0, 2, 150, 0}}},
+		{"grouped by day", []types.HostContract{
+			lockedContract(unresolved, now.AddDate(0, 0, 10), 300),
+			lockedContract(unresolved, now.AddDate(0, 0, 2), 100),
+			lockedContract(unresolved, now.AddDate(0, 0, 2).Add(6*time.Hour), 200),
+			lockedContract(types.ContractStatusSucceeded, now.AddDate(0, 0, 2), 1000),
+		}, [][4]uint64{{2, 2, 300, 300}, {10, 1, 300, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeline := CollateralTimeline(tt.contracts, now)
+			if len(timeline) != len(tt.unlocks) {
+				t.Fatalf("expected %d unlocks, got %d", len(tt.unlocks), len(timeline))
+			}
+
+			for i, unlock := range timeline {
+				expected := tt.unlocks[i]
+
+				if day := today.AddDate(0, 0, int(expected[0])); !unlock.Timestamp.Equal(day) {
+					t.Fatalf("expected unlock on %s, got %s", day, unlock.Timestamp)
+				} else if unlock.Contracts != expected[1] {
+					t.Fatalf("expected %d contracts, got %d", expected[1], unlock.Contracts)
+				} else if !unlock.Unlocked.Equals64(expected[2]) {
+					t.Fatalf("expected %d unlocked, got %s", expected[2], unlock.Unlocked)
+				} else if !unlock.RemainingLocked.Equals64(expected[3]) {
+					t.Fatalf("expected %d remaining, got %s", expected[3], unlock.RemainingLocked)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package forecast
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// ConfidenceLevel the confidence level of the forecast's bands
+	ConfidenceLevel = 0.9
+	// confidenceZ the z-score of the confidence level
+	confidenceZ = 1.645
+
+	// historyMonths the number of full months used to fit the new contract
+	// trend
+	historyMonths = 12
+)
+
+type contractStats struct {
+	successRate     float64
+	averageRevenue  float64
+	averageDuration time.Duration
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthIndex returns the number of months between the start month and the
+// month of the timestamp
+func monthIndex(start, t time.Time) int {
+	t = t.UTC()
+	return (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+}
+
+func toCurrency(v float64) siatypes.Currency {
+	if v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return siatypes.ZeroCurrency
+	}
+
+	i, _ := big.NewFloat(v).Int(nil)
+
+	return siatypes.NewCurrency(i)
+}
+
+func toUint64(v float64) uint64 {
+	if v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+
+	return uint64(math.Round(v))
+}
+
+// calcContractStats calculates the historical success rate, the average
+// revenue of successful contracts, and the average contract duration
+func calcContractStats(contracts []types.HostContract) (stats contractStats) {
+	var succeeded, failed int
+	var revenue float64
+	var duration time.Duration
+
+	for _, contract := range contracts {
+		duration += contract.ExpirationTimestamp.Sub(contract.NegotiationTimestamp)
+
+		switch contract.Status {
+		case types.ContractStatusSucceeded:
+			succeeded++
+			revenue += contract.EarnedRevenue.Float64()
+		case types.ContractStatusFailed:
+			failed++
+		}
+	}
+
+	stats.successRate = 1
+	if succeeded+failed > 0 {
+		stats.successRate = float64(succeeded) / float64(succeeded+failed)
+	}
+
+	if succeeded > 0 {
+		stats.averageRevenue = revenue / float64(succeeded)
+	}
+
+	if len(contracts) > 0 {
+		stats.averageDuration = duration / time.Duration(len(contracts))
+	}
+
+	return
+}
+
+// fitNewContracts fits the trend of new contracts per month. x is the month
+// index relative to the current month. Only full months after the first
+// snapshot are included.
+func fitNewContracts(snapshots []types.HostSnapshot, current time.Time) Linear {
+	counts := make(map[int]float64)
+	first := 0
+
+	for _, snapshot := range snapshots {
+		i := monthIndex(current, snapshot.Timestamp)
+		if i >= 0 || i < -historyMonths {
+			continue
+		}
+
+		if i < first {
+			first = i
+		}
+
+		counts[i] += float64(snapshot.NewContracts)
+	}
+
+	var xs, ys []float64
+	for i := first; i < 0; i++ {
+		xs = append(xs, float64(i))
+		ys = append(ys, counts[i])
+	}
+
+	return FitLinear(xs, ys)
+}
+
+// fitUsedStorage fits the trend of used storage. x is the number of days
+// relative to now.
+func fitUsedStorage(metadata []types.HostMeta, now time.Time) (trend Linear, totalStorage uint64) {
+	var xs, ys []float64
+
+	for _, meta := range metadata {
+		xs = append(xs, meta.Timestamp.Sub(now).Hours()/24)
+		ys = append(ys, float64(meta.UsedStorage))
+		totalStorage = meta.TotalStorage
+	}
+
+	return FitLinear(xs, ys), totalStorage
+}
+
+// Build projects the host's revenue, new contracts, and used storage for the
+// specified number of months starting with the current month. Revenue is
+// expected from unresolved contracts expiring in each month and from new
+// contracts projected by the trend, weighted by the historical success rate.
+func Build(now time.Time, months int, contracts []types.HostContract, snapshots []types.HostSnapshot, metadata []types.HostMeta) (forecast types.HostForecast) {
+	current := monthStart(now)
+	stats := calcContractStats(contracts)
+	p := stats.successRate
+
+	forecast.SuccessRate = p
+	forecast.AverageRevenue = toCurrency(stats.averageRevenue)
+	forecast.AverageDuration = stats.averageDuration
+	forecast.ConfidenceInterval = ConfidenceLevel
+	forecast.Months = make([]types.ForecastMonth, months)
+
+	expected := make([]float64, months)
+	variance := make([]float64, months)
+
+	for _, contract := range contracts {
+		if contract.Status != types.ContractStatusUnresolved {
+			continue
+		}
+
+		// contracts past expiration are waiting for a proof in the current month
+		i := monthIndex(current, contract.ExpirationTimestamp)
+		if i < 0 {
+			i = 0
+		} else if i >= months {
+			continue
+		}
+
+		// each contract either succeeds with its full value or fails
+		v, _ := contract.PotentialRevenue.Float64()
+		expected[i] += v * p
+		variance[i] += v * v * p * (1 - p)
+
+		forecast.Months[i].ExpiringContracts++
+		forecast.Months[i].UnresolvedRevenue = forecast.Months[i].UnresolvedRevenue.Add(contract.PotentialRevenue)
+	}
+
+	newTrend := fitNewContracts(snapshots, current)
+	storageTrend, totalStorage := fitUsedStorage(metadata, now)
+	durationMonths := int(math.Round(stats.averageDuration.Hours() / (24 * 30)))
+
+	forecast.TotalStorage = totalStorage
+
+	for i := range forecast.Months {
+		month := &forecast.Months[i]
+		month.Month = current.AddDate(0, i, 0)
+
+		if i > 0 {
+			n := newTrend.Predict(float64(i))
+			month.NewContracts = toUint64(n)
+			month.NewContractsLow = toUint64(n - confidenceZ*newTrend.StdErr)
+			month.NewContractsHigh = toUint64(n + confidenceZ*newTrend.StdErr)
+		}
+
+		// revenue from projected contracts formed in a future month that
+		// expire this month
+		if formed := i - durationMonths; stats.averageDuration > 0 && formed > 0 {
+			n := math.Max(newTrend.Predict(float64(formed)), 0)
+			expected[i] += n * stats.averageRevenue * p
+			sd := newTrend.StdErr * stats.averageRevenue * p
+			variance[i] += sd * sd
+		}
+
+		band := confidenceZ * math.Sqrt(variance[i])
+		month.ExpectedRevenue = toCurrency(expected[i])
+		month.RevenueLow = toCurrency(expected[i] - band)
+		month.RevenueHigh = toCurrency(expected[i] + band)
+
+		days := month.Month.AddDate(0, 1, 0).Sub(now).Hours() / 24
+		used := storageTrend.Predict(days)
+		month.UsedStorage = toUint64(used)
+		month.UsedStorageLow = toUint64(used - confidenceZ*storageTrend.StdErr)
+		month.UsedStorageHigh = toUint64(used + confidenceZ*storageTrend.StdErr)
+
+		if totalStorage > 0 {
+			month.StorageUtilization = float64(month.UsedStorage) / float64(totalStorage)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,106 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+// resolvedContract returns a contract with the status that earned the revenue
+func resolvedContract(status string, earned uint64) types.HostContract {
+	return types.HostContract{
+		Status:        status,
+		EarnedRevenue: types.BigNumber{}.AddCurrency(siatypes.NewCurrency64(earned)),
+	}
+}
+
+// unresolvedContract returns an unresolved contract expiring at the timestamp
+func unresolvedContract(expiration time.Time, revenue uint64) types.HostContract {
+	return types.HostContract{
+		Status:              types.ContractStatusUnresolved,
+		ExpirationTimestamp: expiration,
+		PotentialRevenue:    siatypes.NewCurrency64(revenue),
+	}
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		contracts   []types.HostContract
+		successRate float64
+		// expected revenue, low, and high of each month
+		revenue [][3]uint64
+		// expiring contracts of each month
+		expiring []uint64
+	}{
+		{
+			name:        "no history",
+			successRate: 1,
+			revenue:     [][3]uint64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}},
+			expiring:    []uint64{0, 0, 0},
+		},
+		{
+			name: "certain success",
+			contracts: []types.HostContract{
+				resolvedContract(types.ContractStatusSucceeded, 800),
+				unresolvedContract(now.AddDate(0, 1, 0), 1000),
+			},
+			successRate: 1,
+			revenue:     [][3]uint64{{0, 0, 0}, {1000, 1000, 1000}, {0, 0, 0}},
+			expiring:    []uint64{0, 1, 0},
+		},
+		{
+			name: "half failed",
+			contracts: []types.HostContract{
+				resolvedContract(types.ContractStatusSucceeded, 800),
+				resolvedContract(types.ContractStatusFailed, 0),
+				unresolvedContract(now.AddDate(0, 1, 0), 1000),
+			},
+			successRate: 0.5,
+			// the band is 1.645 standard deviations of 500 hastings
+			revenue:  [][3]uint64{{0, 0, 0}, {500, 0, 1322}, {0, 0, 0}},
+			expiring: []uint64{0, 1, 0},
+		},
+		{
+			name: "past expiration",
+			contracts: []types.HostContract{
+				unresolvedContract(now.AddDate(0, -1, 0), 1000),
+				unresolvedContract(now.AddDate(0, 5, 0), 1000),
+			},
+			successRate: 1,
+			revenue:     [][3]uint64{{1000, 1000, 1000}, {0, 0, 0}, {0, 0, 0}},
+			expiring:    []uint64{1, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := Build(now, len(tt.revenue), tt.contracts, nil, nil)
+
+			if !approxEqual(forecast.SuccessRate, tt.successRate) {
+				t.Fatalf("expected success rate %g, got %g", tt.successRate, forecast.SuccessRate)
+			} else if len(forecast.Months) != len(tt.revenue) {
+				t.Fatalf("expected %d months, got %d", len(tt.revenue), len(forecast.Months))
+			}
+
+			for i, month := range forecast.Months {
+				if expected := monthStart(now).AddDate(0, i, 0); !month.Month.Equal(expected) {
+					t.Fatalf("month %d: expected %s, got %s", i, expected, month.Month)
+				} else if month.ExpiringContracts != tt.expiring[i] {
+					t.Fatalf("month %d: expected %d expiring contracts, got %d", i, tt.expiring[i], month.ExpiringContracts)
+				}
+
+				got := [3]siatypes.Currency{month.ExpectedRevenue, month.RevenueLow, month.RevenueHigh}
+				for j, v := range tt.revenue[i] {
+					if !got[j].Equals64(v) {
+						t.Fatalf("month %d: expected revenue %v, got %v", i, tt.revenue[i], got)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package forecast
+
+import "math"
+
+// Linear a least squares linear fit of y = Intercept + Slope * x
+type Linear struct {
+	Slope     float64
+	Intercept float64
+	// StdErr the standard error of the residuals, used to build
+	// confidence bands around predictions
+	StdErr float64
+	N      int
+}
+
+// FitLinear fits a line to the points using ordinary least squares. With a
+// single point the line is flat, with no points every prediction is zero.
+func FitLinear(xs, ys []float64) (l Linear) {
+	var meanX, meanY, sxx, sxy, rss float64
+
+	l.N = len(xs)
+
+	if l.N == 0 {
+		return
+	}
+
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+
+	meanX /= float64(l.N)
+	meanY /= float64(l.N)
+
+	for i := range xs {
+		dx := xs[i] - meanX
+		sxx += dx * dx
+		sxy += dx * (ys[i] - meanY)
+	}
+
+	if sxx != 0 {
+		l.Slope = sxy / sxx
+	}
+
+	l.Intercept = meanY - l.Slope*meanX
+
+	if l.N <= 2 {
+		return
+	}
+
+	for i := range xs {
+		r := ys[i] - l.Predict(xs[i])
+		rss += r * r
+	}
+
+	l.StdErr = math.Sqrt(rss / float64(l.N-2))
+
+	return
+}
+
+// Predict returns the fitted value at x
+func (l Linear) Predict(x float64) float64 {
+	return l.Intercept + l.Slope*x
+}
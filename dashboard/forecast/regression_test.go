@@ -0,0 +1,49 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+// approxEqual returns true if the values are within a small tolerance
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFitLinear(t *testing.T) {
+	tests := []struct {
+		name      string
+		xs, ys    []float64
+		slope     float64
+		intercept float64
+		stdErr    float64
+	}{
+		{"no samples", nil, nil, 0, 0, 0},
+		{"one sample", []float64{3}, []float64{7}, 0, 7, 0},
+		{"same x", []float64{2, 2, 2}, []float64{1, 2, 3}, 0, 2, math.Sqrt(2)},
+		{"flat", []float64{0, 1, 2, 3}, []float64{5, 5, 5, 5}, 0, 5, 0},
+		{"increasing", []float64{0, 1, 2, 3}, []float64{1, 3, 5, 7}, 2, 1, 0},
+		{"decreasing", []float64{-3, -2, -1, 0}, []float64{10, 8, 6, 4}, -2, 4, 0},
+		{"noisy", []float64{0, 1, 2, 3}, []float64{0, 2, 2, 4}, 1.2, 0.2, math.Sqrt(0.4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := FitLinear(tt.xs, tt.ys)
+
+			if l.N != len(tt.xs) {
+				t.Fatalf("expected %d samples, got %d", len(tt.xs), l.N)
+			} else if !approxEqual(l.Slope, tt.slope) {
+				t.Fatalf("expected slope %g, got %g", tt.slope, l.Slope)
+			} else if !approxEqual(l.Intercept, tt.intercept) {
+				t.Fatalf("expected intercept %g, got %g", tt.intercept, l.Intercept)
+			} else if !approxEqual(l.StdErr, tt.stdErr) {
+				t.Fatalf("expected standard error %g, got %g", tt.stdErr, l.StdErr)
+			}
+
+			if p := l.Predict(10); !approxEqual(p, tt.intercept+10*tt.slope) {
+				t.Fatalf("expected prediction %g, got %g", tt.intercept+10*tt.slope, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Level the severity of a log entry
+type Level uint8
+
+// Format the encoding of log entries
+type Format string
+
+const (
+	// LevelDebug verbose output, such as individual API requests
+	LevelDebug Level = iota
+	// LevelInfo normal operation
+	LevelInfo
+	// LevelWarn recoverable problems
+	LevelWarn
+	// LevelError failed operations
+	LevelError
+)
+
+const (
+	// FormatLogfmt encodes entries as key=value pairs
+	FormatLogfmt Format = "logfmt"
+	// FormatJSON encodes entries as JSON objects, one per line
+	FormatJSON Format = "json"
+)
+
+type (
+	// output the destination shared by a logger and its children
+	output struct {
+		mu     sync.Mutex
+		w      io.Writer
+		format Format
+	}
+
+	// Logger writes leveled, structured log entries. A nil Logger discards
+	// all entries.
+	Logger struct {
+		out    *output
+		level  Level
+		fields []interface{}
+	}
+
+	// lineWriter adapts a Logger to an io.Writer, each write is logged as a
+	// single entry
+	lineWriter struct {
+		logger *Logger
+		level  Level
+	}
+)
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel returns the level with the name
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, must be debug, info, warn, or error", s)
+	}
+}
+
+// ParseFormat returns the format with the name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatLogfmt, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q, must be logfmt or json", s)
+	}
+}
+
+// New creates a logger writing entries at or above the level to w
+func New(w io.Writer, level Level, format Format) *Logger {
+	return &Logger{
+		out: &output{
+			w:      w,
+			format: format,
+		},
+		level: level,
+	}
+}
+
+// With returns a child logger that adds the key value pairs to every entry
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	fields := make([]interface{}, 0, len(l.fields)+len(keyvals))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+
+	return &Logger{
+		out:    l.out,
+		level:  l.level,
+		fields: fields,
+	}
+}
+
+// Enabled returns true if entries at the level are written
+func (l *Logger) Enabled(level Level) bool {
+	return l != nil && level >= l.level
+}
+
+// Debug writes a debug entry
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.log(LevelDebug, msg, keyvals)
+}
+
+// Info writes an info entry
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.log(LevelInfo, msg, keyvals)
+}
+
+// Warn writes a warning entry
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.log(LevelWarn, msg, keyvals)
+}
+
+// Error writes an error entry
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.log(LevelError, msg, keyvals)
+}
+
+// Writer returns an io.Writer that logs each write as an entry at the level.
+// It is used to redirect the standard library's logger.
+func (l *Logger) Writer(level Level) io.Writer {
+	return lineWriter{logger: l, level: level}
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w.logger.log(w.level, string(bytes.TrimRight(p, "\r\n")), nil)
+	return len(p), nil
+}
+
+func (l *Logger) log(level Level, msg string, keyvals []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	kv := make([]interface{}, 0, 6+len(l.fields)+len(keyvals))
+	kv = append(kv, "time", time.Now().UTC(), "level", level.String(), "msg", msg)
+	kv = append(kv, l.fields...)
+	kv = append(kv, keyvals...)
+
+	var buf bytes.Buffer
+	if l.out.format == FormatJSON {
+		encodeJSON(&buf, kv)
+	} else {
+		encodeLogfmt(&buf, kv)
+	}
+	buf.WriteByte('\n')
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+
+	// there is nowhere left to report a failed write
+	_, _ = l.out.w.Write(buf.Bytes())
+}
+
+// pairs calls fn for each key value pair. A missing value is logged as
+// empty.
+func pairs(keyvals []interface{}, fn func(key string, value interface{})) {
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{}
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		fn(fmt.Sprint(keyvals[i]), value)
+	}
+}
+
+// stringValue returns the text representation of a value
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func encodeLogfmt(buf *bytes.Buffer, keyvals []interface{}) {
+	pairs(keyvals, func(key string, value interface{}) {
+		if buf.Len() != 0 {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(logfmtQuote(key))
+		buf.WriteByte('=')
+		buf.WriteString(logfmtQuote(stringValue(value)))
+	})
+}
+
+// logfmtQuote quotes the string if it contains spaces, quotes, equal signs,
+// or control characters
+func logfmtQuote(s string) string {
+	if len(s) == 0 {
+		return `""`
+	}
+
+	for _, r := range s {
+		if r == ' ' || r == '"' || r == '=' || r == '\\' || unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return strconv.Quote(s)
+		}
+	}
+
+	return s
+}
+
+func encodeJSON(buf *bytes.Buffer, keyvals []interface{}) {
+	buf.WriteByte('{')
+	pairs(keyvals, func(key string, value interface{}) {
+		if buf.Len() != 1 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(jsonValue(value))
+	})
+	buf.WriteByte('}')
+}
+
+// jsonValue encodes the value as JSON. Errors and durations are encoded as
+// strings, values that cannot be encoded fall back to their text
+// representation.
+func jsonValue(v interface{}) []byte {
+	switch v.(type) {
+	case error, time.Duration:
+		v = stringValue(v)
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		buf, _ = json.Marshal(stringValue(v))
+	}
+
+	return buf
+}
@@ -0,0 +1,230 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat the timestamp added to the names of rotated log files
+const backupTimeFormat = "20060102T150405"
+
+// openFile opens the log file, replaced in tests
+var openFile = os.OpenFile
+
+type (
+	// RotateOptions options when rotating a log file
+	RotateOptions struct {
+		// MaxSize the size in bytes the log can grow to before it is
+		// rotated. Zero disables size based rotation.
+		MaxSize int64
+		// MaxAge rotated logs older than MaxAge are removed. Zero keeps
+		// rotated logs forever.
+		MaxAge time.Duration
+	}
+
+	// RotatingFile an append-only log file that is rotated when it grows
+	// larger than the max size or when the day changes. Rotated files are
+	// renamed with the time of their last write.
+	RotatingFile struct {
+		mu   sync.Mutex
+		path string
+		opts RotateOptions
+
+		f         *os.File
+		closed    bool
+		size      int64
+		lastWrite time.Time
+	}
+)
+
+// OpenRotatingFile opens or creates the log file at the path
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path: path,
+		opts: opts,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	if err := rf.removeExpired(); err != nil {
+		_ = rf.f.Close()
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := openFile(rf.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open log: %w", err)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat log: %w", err)
+	}
+
+	rf.f = f
+	rf.size = stat.Size()
+	rf.lastWrite = stat.ModTime()
+
+	return nil
+}
+
+// backupPath returns an unused path for a rotated log, the timestamp is added
+// between the name and extension. A counter is added to the timestamp if
+// the log was already rotated within the same second.
+func (rf *RotatingFile) backupPath(timestamp time.Time) (string, error) {
+	ext := filepath.Ext(rf.path)
+	name := strings.TrimSuffix(rf.path, ext)
+	stamp := timestamp.Format(backupTimeFormat)
+
+	for i := 0; ; i++ {
+		path := fmt.Sprintf("%s-%s%s", name, stamp, ext)
+		if i > 0 {
+			path = fmt.Sprintf("%s-%s-%d%s", name, stamp, i, ext)
+		}
+
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path, nil
+		} else if err != nil {
+			return "", fmt.Errorf("stat backup: %w", err)
+		}
+	}
+}
+
+// removeExpired removes rotated logs that are older than the max age
+func (rf *RotatingFile) removeExpired() error {
+	if rf.opts.MaxAge <= 0 {
+		return nil
+	}
+
+	ext := filepath.Ext(rf.path)
+	prefix := strings.TrimSuffix(filepath.Base(rf.path), ext) + "-"
+	min := time.Now().Add(-rf.opts.MaxAge)
+
+	entries, err := os.ReadDir(filepath.Dir(rf.path))
+	if err != nil {
+		return fmt.Errorf("read log directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		// strip the counter of logs rotated within the same second
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if i := strings.IndexByte(stamp, '-'); i >= 0 {
+			stamp = stamp[:i]
+		}
+
+		timestamp, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil || !timestamp.Before(min) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(filepath.Dir(rf.path), name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove log %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// rotate renames the current log and opens a new one. If the rotation fails
+// after the current log is closed, the log is reopened by the next write.
+func (rf *RotatingFile) rotate() error {
+	backup, err := rf.backupPath(rf.lastWrite)
+	if err != nil {
+		return err
+	}
+
+	err = rf.f.Close()
+	rf.f = nil
+	if err != nil {
+		return fmt.Errorf("close log: %w", err)
+	}
+
+	if err := os.Rename(rf.path, backup); err != nil {
+		// keep writing to the current log
+		if err := rf.open(); err != nil {
+			return err
+		}
+		return fmt.Errorf("rename log: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	return rf.removeExpired()
+}
+
+// shouldRotate returns true if writing n bytes at the time should start a new
+// log
+func (rf *RotatingFile) shouldRotate(n int, now time.Time) bool {
+	if rf.size == 0 {
+		return false
+	}
+
+	if rf.opts.MaxSize > 0 && rf.size+int64(n) > rf.opts.MaxSize {
+		return true
+	}
+
+	ly, lm, ld := rf.lastWrite.Date()
+	y, m, d := now.Date()
+	return ly != y || lm != m || ld != d
+}
+
+// Write implements io.Writer
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return 0, os.ErrClosed
+	} else if rf.f == nil {
+		// a previous rotation failed after closing the log
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now()
+	if rf.shouldRotate(len(p), now) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	rf.lastWrite = now
+
+	return n, err
+}
+
+// Close closes the log file
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	rf.closed = true
+	if rf.f == nil {
+		return nil
+	}
+
+	err := rf.f.Close()
+	rf.f = nil
+	return err
+}
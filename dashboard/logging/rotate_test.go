@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readLogs returns the contents of every log in the directory
+func readLogs(t *testing.T, dir string) (logs []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		buf, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logs = append(logs, string(buf))
+	}
+
+	return
+}
+
+func TestRotateSameSecond(t *testing.T) {
+	dir := t.TempDir()
+
+	rf, err := OpenRotatingFile(filepath.Join(dir, "dashboard.log"), RotateOptions{MaxSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = rf.Close()
+	}()
+
+	lines := []string{"first\n", "second\n", "third\n"}
+	for _, line := range lines {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs := readLogs(t, dir)
+	if len(logs) != len(lines) {
+		t.Fatalf("expected %d logs, got %d", len(lines), len(logs))
+	}
+
+	all := strings.Join(logs, "")
+	for _, line := range lines {
+		if !strings.Contains(all, line) {
+			t.Fatalf("expected %q to be logged, got %q", line, logs)
+		}
+	}
+}
+
+func TestRotateOpenFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dashboard.log")
+
+	rf, err := OpenRotatingFile(path, RotateOptions{MaxSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = rf.Close()
+	}()
+
+	if _, err := rf.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	open := openFile
+	t.Cleanup(func() {
+		openFile = open
+	})
+
+	openFile = func(string, int, os.FileMode) (*os.File, error) {
+		return nil, errors.New("open failed")
+	}
+
+	if _, err := rf.Write([]byte("dropped\n")); err == nil {
+		t.Fatal("expected the rotation to fail")
+	} else if rf.f != nil {
+		t.Fatal("expected the closed log to be released")
+	}
+
+	openFile = open
+
+	if _, err := rf.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "second\n" {
+		t.Fatalf("expected the log to be reopened, got %q", buf)
+	}
+}
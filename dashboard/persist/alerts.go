@@ -0,0 +1,112 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+// alertID orders occurrences by hour, the alert is appended so each alert is
+// only stored once per hour
+func alertID(occurrence types.AlertOccurrence) []byte {
+	alert := occurrence.HostAlert
+	buf := timeID(occurrence.Timestamp)
+
+	buf = append(buf, alert.Type...)
+	buf = append(buf, 0)
+	buf = append(buf, alert.Severity...)
+	buf = append(buf, 0)
+	return append(buf, alert.Text...)
+}
+
+// SaveAlertOccurrences stores the alert occurrences. Occurrences of an alert
+// that was already stored for the same hour are ignored.
+func SaveAlertOccurrences(occurrences []types.AlertOccurrence) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketAlertOccurrences)
+
+		for _, occurrence := range occurrences {
+			id := alertID(occurrence)
+			if bucket.Get(id) != nil {
+				continue
+			}
+
+			buf, err := json.Marshal(occurrence)
+			if err != nil {
+				return fmt.Errorf("json encode: %w", err)
+			}
+
+			if err := bucket.Put(id, buf); err != nil {
+				return fmt.Errorf("unable to put alert: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetAlertOccurrences returns the alert occurrences between start (inclusive)
+// and end (exclusive) ordered by time
+func GetAlertOccurrences(start, end time.Time) (occurrences []types.AlertOccurrence, err error) {
+	if start.After(end) {
+		err = errors.New("start must be before end")
+		return
+	}
+
+	startID, endID := timeID(start), timeID(end)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketAlertOccurrences).Cursor()
+
+		for key, buf := c.Seek(startID); key != nil && bytes.Compare(key[:8], endID) <= 0; key, buf = c.Next() {
+			var occurrence types.AlertOccurrence
+
+			if err := json.Unmarshal(buf, &occurrence); err != nil {
+				return fmt.Errorf("unable to decode alert: %w", err)
+			}
+
+			if occurrence.Timestamp.Before(start) || !occurrence.Timestamp.Before(end) {
+				continue
+			}
+
+			occurrences = append(occurrences, occurrence)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// PruneAlertOccurrences removes the alert occurrences from the hours before
+// the timestamp
+func PruneAlertOccurrences(before time.Time) (pruned int, err error) {
+	beforeID := timeID(before)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketAlertOccurrences)
+		c := bucket.Cursor()
+
+		// deleting while iterating skips keys, collect them first
+		var keys [][]byte
+		for key, _ := c.First(); key != nil && bytes.Compare(key[:8], beforeID) < 0; key, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return fmt.Errorf("unable to delete alert: %w", err)
+			}
+		}
+
+		pruned = len(keys)
+		return nil
+	})
+
+	return
+}
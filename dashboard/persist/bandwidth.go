@@ -0,0 +1,110 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+var keyBandwidthCounters = []byte("counters")
+
+// SaveBandwidthReading stores the bandwidth counters and adds the usage to the
+// usage already recorded for the hour in a single transaction
+func SaveBandwidthReading(counters types.BandwidthCounters, usage types.BandwidthUsage) error {
+	usage.Timestamp = usage.Timestamp.Truncate(time.Hour).UTC()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		buf, err := json.Marshal(counters)
+		if err != nil {
+			return fmt.Errorf("json encode: %w", err)
+		}
+
+		if err := tx.Bucket(bucketBandwidthCounters).Put(keyBandwidthCounters, buf); err != nil {
+			return fmt.Errorf("unable to put counters: %w", err)
+		}
+
+		if usage.Upload == 0 && usage.Download == 0 {
+			return nil
+		}
+
+		var existing types.BandwidthUsage
+
+		bucket := tx.Bucket(bucketBandwidth)
+		id := timeID(usage.Timestamp)
+
+		if buf := bucket.Get(id); buf != nil {
+			if err := json.Unmarshal(buf, &existing); err != nil {
+				return fmt.Errorf("unable to decode usage: %w", err)
+			}
+		}
+
+		usage.Upload += existing.Upload
+		usage.Download += existing.Download
+
+		buf, err = json.Marshal(usage)
+		if err != nil {
+			return fmt.Errorf("json encode: %w", err)
+		}
+
+		if err := bucket.Put(id, buf); err != nil {
+			return fmt.Errorf("unable to put usage: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetBandwidthCounters returns the last stored bandwidth counters. exists is
+// false if the counters have never been stored.
+func GetBandwidthCounters() (counters types.BandwidthCounters, exists bool, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		buf := tx.Bucket(bucketBandwidthCounters).Get(keyBandwidthCounters)
+		if buf == nil {
+			return nil
+		}
+
+		exists = true
+
+		if err := json.Unmarshal(buf, &counters); err != nil {
+			return fmt.Errorf("unable to decode counters: %w", err)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// GetBandwidthUsage returns the hourly bandwidth usage between two timestamps
+// (inclusive)
+func GetBandwidthUsage(start, end time.Time) (usage []types.BandwidthUsage, err error) {
+	if start.After(end) {
+		err = errors.New("start must be before end")
+		return
+	}
+
+	startID, endID := timeID(start), timeID(end)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketBandwidth).Cursor()
+
+		for key, buf := c.Seek(startID); key != nil && bytes.Compare(key, endID) <= 0; key, buf = c.Next() {
+			var hour types.BandwidthUsage
+
+			if err := json.Unmarshal(buf, &hour); err != nil {
+				return fmt.Errorf("unable to decode usage: %w", err)
+			}
+
+			usage = append(usage, hour)
+		}
+
+		return nil
+	})
+
+	return
+}
@@ -0,0 +1,48 @@
+package persist
+
+import (
+	"encoding/binary"
+	"time"
+
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+func heightID(height uint64) []byte {
+	buf := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(buf, height)
+
+	return buf
+}
+
+// GetBlockTimestamp returns the stored timestamp of the block at the specified
+// height. exists is false if the block has not been stored.
+func GetBlockTimestamp(height uint64) (timestamp time.Time, exists bool, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		buf := tx.Bucket(bucketBlockTimestamp).Get(heightID(height))
+
+		if len(buf) != 8 {
+			return nil
+		}
+
+		exists = true
+		timestamp = time.Unix(int64(binary.BigEndian.Uint64(buf)), 0)
+
+		return nil
+	})
+
+	return
+}
+
+// SaveBlockTimestamp stores the timestamp of the block at the specified height.
+// Safe to call from multiple goroutines, concurrent calls are batched into a
+// single write.
+func SaveBlockTimestamp(height uint64, timestamp time.Time) error {
+	buf := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(buf, uint64(timestamp.Unix()))
+
+	return db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketBlockTimestamp).Put(heightID(height), buf)
+	})
+}
@@ -0,0 +1,47 @@
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+// GetContracts returns all contracts stored in the database
+func GetContracts() (contracts []types.HostContract, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketContracts).ForEach(func(_, buf []byte) error {
+			var contract types.HostContract
+
+			if err := json.Unmarshal(buf, &contract); err != nil {
+				return fmt.Errorf("unable to decode contract: %w", err)
+			}
+
+			contracts = append(contracts, contract)
+
+			return nil
+		})
+	})
+
+	return
+}
+
+// TransactionConfirmed returns true if the transaction has previously been
+// marked as confirmed
+func TransactionConfirmed(id string) (confirmed bool, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		confirmed = tx.Bucket(bucketConfirmedTxns).Get([]byte(id)) != nil
+		return nil
+	})
+
+	return
+}
+
+// SaveConfirmedTransaction marks the transaction as confirmed. Safe to call
+// from multiple goroutines, concurrent calls are batched into a single write.
+func SaveConfirmedTransaction(id string) error {
+	return db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketConfirmedTxns).Put([]byte(id), []byte{1})
+	})
+}
@@ -0,0 +1,173 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+// eventID orders events by time, the contract id is appended to keep keys
+// unique when multiple contracts change at the same time
+func eventID(event types.ContractEvent) []byte {
+	buf := make([]byte, 8, 8+len(event.ContractID))
+
+	binary.BigEndian.PutUint64(buf, uint64(event.Timestamp.UnixNano()))
+
+	return append(buf, event.ContractID...)
+}
+
+func putEvent(bucket *bolt.Bucket, event types.ContractEvent) error {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("json encode: %w", err)
+	}
+
+	if err := bucket.Put(eventID(event), buf); err != nil {
+		return fmt.Errorf("unable to put event: %w", err)
+	}
+
+	return nil
+}
+
+// markEventsApplied removes the events from the pending events. Status
+// changes are kept in the contract events without the contracts' state.
+func markEventsApplied(tx *bolt.Tx, events []types.ContractEvent) error {
+	pending := tx.Bucket(bucketPendingEvents)
+	applied := tx.Bucket(bucketContractEvents)
+
+	for _, event := range events {
+		if err := pending.Delete(eventID(event)); err != nil {
+			return fmt.Errorf("unable to delete pending event: %w", err)
+		}
+
+		if event.Type == types.ContractEventUpdated {
+			continue
+		}
+
+		event.Previous, event.Current = nil, nil
+		if err := putEvent(applied, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getPendingEvents returns the pending events ordered by time
+func getPendingEvents(tx *bolt.Tx) (events []types.ContractEvent, err error) {
+	err = tx.Bucket(bucketPendingEvents).ForEach(func(_, buf []byte) error {
+		var event types.ContractEvent
+
+		if err := json.Unmarshal(buf, &event); err != nil {
+			return fmt.Errorf("unable to decode event: %w", err)
+		}
+
+		events = append(events, event)
+
+		return nil
+	})
+
+	return
+}
+
+// SaveContractChanges stores the changed contracts, removes the removed
+// contracts, and stores the events as pending in a single transaction. The
+// events' changes are applied to the snapshots by ApplyContractEvents.
+func SaveContractChanges(contracts []types.HostContract, removed []string, events []types.ContractEvent) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketContracts)
+
+		for _, contract := range contracts {
+			buf, err := json.Marshal(contract)
+			if err != nil {
+				return fmt.Errorf("json encode: %w", err)
+			}
+
+			if err := bucket.Put([]byte(contract.ID), buf); err != nil {
+				return fmt.Errorf("unable to put contract: %w", err)
+			}
+		}
+
+		for _, id := range removed {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return fmt.Errorf("unable to delete contract: %w", err)
+			}
+		}
+
+		pending := tx.Bucket(bucketPendingEvents)
+		for _, event := range events {
+			if err := putEvent(pending, event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetPendingContractEvents returns the events that have not been applied to
+// the snapshots, ordered by time
+func GetPendingContractEvents() (events []types.ContractEvent, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		events, err = getPendingEvents(tx)
+		return err
+	})
+
+	return
+}
+
+// ApplyContractEvents stores the updated snapshots, removes the snapshots of
+// the removed hours, and marks the events as applied in a single transaction
+func ApplyContractEvents(snapshots []types.HostSnapshot, removed []time.Time, events []types.ContractEvent) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketHostSnapshots)
+
+		for _, snapshot := range snapshots {
+			if err := putSnapshot(bucket, snapshot); err != nil {
+				return err
+			}
+		}
+
+		for _, timestamp := range removed {
+			if err := bucket.Delete(timeID(timestamp)); err != nil {
+				return fmt.Errorf("unable to delete snapshot: %w", err)
+			}
+		}
+
+		return markEventsApplied(tx, events)
+	})
+}
+
+// PruneContractEvents removes the applied contract events that occurred before
+// the timestamp
+func PruneContractEvents(before time.Time) (pruned int, err error) {
+	beforeID := make([]byte, 8)
+	binary.BigEndian.PutUint64(beforeID, uint64(before.UnixNano()))
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketContractEvents)
+		c := bucket.Cursor()
+
+		// deleting while iterating skips keys, collect them first
+		var keys [][]byte
+		for key, _ := c.First(); key != nil && bytes.Compare(key[:8], beforeID) < 0; key, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return fmt.Errorf("unable to delete event: %w", err)
+			}
+		}
+
+		pruned = len(keys)
+		return nil
+	})
+
+	return
+}
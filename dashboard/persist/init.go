@@ -6,13 +6,18 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/siacentral/sia-host-dashboard/dashboard/logging"
 	"gitlab.com/NebulousLabs/bolt"
 )
 
+var logger *logging.Logger
+
 //InitializeDB opens or creates the database at the specified path
-func InitializeDB(dataPath string) error {
+func InitializeDB(dataPath string, log *logging.Logger) error {
 	var err error
 
+	logger = log
+
 	if _, err := os.Stat(dataPath); err != nil {
 		if !os.IsNotExist(err) {
 			return fmt.Errorf("stat datapath: %w", err)
@@ -23,30 +28,78 @@ func InitializeDB(dataPath string) error {
 		}
 	}
 
-	db, err = bolt.Open(filepath.Join(dataPath, "hoststats.db"), 0600, &bolt.Options{Timeout: 5 * time.Second})
+	start := time.Now()
+	path := filepath.Join(dataPath, "hoststats.db")
+	db, err = bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
 
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	return db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucketHostMeta)
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if tx.Bucket(name) != nil {
+				continue
+			}
+
+			if _, err := tx.CreateBucket(name); err != nil {
+				return fmt.Errorf("create %s bucket: %w", name, err)
+			}
 
-		if err != nil {
-			return fmt.Errorf("create metadata bucket: %w", err)
+			logger.Debug("created bucket", "bucket", string(name))
 		}
 
-		_, err = tx.CreateBucketIfNotExists(bucketHostSnapshots)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("opened database", "path", path, "duration", time.Since(start))
+
+	return nil
+}
+
+// OpenReadOnly opens the existing database at the specified path without
+// write access. The database is locked while the dashboard is running so
+// opening it fails after the timeout.
+func OpenReadOnly(dataPath string, timeout time.Duration, log *logging.Logger) error {
+	var err error
 
-		if err != nil {
-			return fmt.Errorf("create snapshots bucket: %w", err)
+	logger = log
+
+	path := filepath.Join(dataPath, "hoststats.db")
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("stat database: %w", err)
+	}
+
+	db, err = bolt.Open(path, 0600, &bolt.Options{Timeout: timeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	// buckets are only created when the database is opened for writing,
+	// reading from a missing bucket would panic
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if tx.Bucket(name) == nil {
+				return fmt.Errorf("missing %s bucket, start the dashboard to upgrade the database", name)
+			}
 		}
 
 		return nil
 	})
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	return nil
 }
 
 //CloseDB closes the database
 func CloseDB() error {
+	logger.Debug("closing database")
+
 	return db.Close()
 }
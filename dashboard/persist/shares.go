@@ -0,0 +1,82 @@
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+// SaveShareToken stores the share token
+func SaveShareToken(share types.ShareToken) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		buf, err := json.Marshal(share)
+		if err != nil {
+			return fmt.Errorf("json encode: %w", err)
+		}
+
+		if err := tx.Bucket(bucketShareTokens).Put([]byte(share.Token), buf); err != nil {
+			return fmt.Errorf("unable to put share: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetShareToken returns the share token. exists is false if the token was
+// never created or has been revoked.
+func GetShareToken(token string) (share types.ShareToken, exists bool, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		buf := tx.Bucket(bucketShareTokens).Get([]byte(token))
+		if buf == nil {
+			return nil
+		}
+
+		exists = true
+
+		if err := json.Unmarshal(buf, &share); err != nil {
+			return fmt.Errorf("unable to decode share: %w", err)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// GetShareTokens returns all share tokens
+func GetShareTokens() (shares []types.ShareToken, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketShareTokens).ForEach(func(k, buf []byte) error {
+			var share types.ShareToken
+
+			if err := json.Unmarshal(buf, &share); err != nil {
+				return fmt.Errorf("unable to decode share: %w", err)
+			}
+
+			shares = append(shares, share)
+			return nil
+		})
+	})
+
+	return
+}
+
+// DeleteShareToken revokes the share token. exists is false if the token did
+// not exist.
+func DeleteShareToken(token string) (exists bool, err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketShareTokens)
+
+		if bucket.Get([]byte(token)) == nil {
+			return nil
+		}
+
+		exists = true
+
+		return bucket.Delete([]byte(token))
+	})
+
+	return
+}
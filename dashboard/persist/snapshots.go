@@ -11,26 +11,109 @@ import (
 	"gitlab.com/NebulousLabs/bolt"
 )
 
+func putSnapshot(bucket *bolt.Bucket, snapshot types.HostSnapshot) error {
+	snapshot.Timestamp = snapshot.Timestamp.Truncate(time.Hour).UTC()
+	buf, err := json.Marshal(snapshot)
+
+	if err != nil {
+		return fmt.Errorf("json encode: %w", err)
+	}
+
+	if err := bucket.Put(timeID(snapshot.Timestamp), buf); err != nil {
+		return fmt.Errorf("unable to put snapshot: %w", err)
+	}
+
+	return nil
+}
+
 //SaveHostSnapshots SaveHostSnapshot
 func SaveHostSnapshots(snapshots ...types.HostSnapshot) error {
 	return db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(bucketHostSnapshots)
 
 		for _, snapshot := range snapshots {
-			snapshot.Timestamp = snapshot.Timestamp.Truncate(time.Hour).UTC()
-			buf, err := json.Marshal(snapshot)
+			if err := putSnapshot(bucket, snapshot); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReplaceHostSnapshots removes all existing snapshots and stores the new
+// snapshots in a single transaction. The snapshots are built from the stored
+// contracts, the pending contract events are marked as applied.
+func ReplaceHostSnapshots(snapshots ...types.HostSnapshot) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketHostSnapshots); err != nil {
+			return fmt.Errorf("unable to delete snapshots: %w", err)
+		}
+
+		bucket, err := tx.CreateBucket(bucketHostSnapshots)
+		if err != nil {
+			return fmt.Errorf("unable to create snapshots: %w", err)
+		}
+
+		for _, snapshot := range snapshots {
+			if err := putSnapshot(bucket, snapshot); err != nil {
+				return err
+			}
+		}
+
+		events, err := getPendingEvents(tx)
+		if err != nil {
+			return err
+		}
+
+		return markEventsApplied(tx, events)
+	})
+}
+
+// GetHostSnapshotHours returns the stored snapshots for the hours of the
+// specified timestamps. Hours without a snapshot are skipped.
+func GetHostSnapshotHours(timestamps ...time.Time) (snapshots []types.HostSnapshot, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketHostSnapshots)
 
-			if err != nil {
-				return fmt.Errorf("json encode: %w", err)
+		for _, timestamp := range timestamps {
+			var snapshot types.HostSnapshot
+
+			buf := bucket.Get(timeID(timestamp))
+			if buf == nil {
+				continue
 			}
 
-			if err := bucket.Put(timeID(snapshot.Timestamp), buf); err != nil {
-				return fmt.Errorf("unable to put snapshot: %w", err)
+			if err := json.Unmarshal(buf, &snapshot); err != nil {
+				return fmt.Errorf("unable to decode snaphot: %w", err)
 			}
+
+			snapshots = append(snapshots, snapshot)
 		}
 
 		return nil
 	})
+
+	return
+}
+
+// GetAllHostSnapshots returns every stored snapshot
+func GetAllHostSnapshots() (snapshots []types.HostSnapshot, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketHostSnapshots).ForEach(func(_, buf []byte) error {
+			var snapshot types.HostSnapshot
+
+			if err := json.Unmarshal(buf, &snapshot); err != nil {
+				return fmt.Errorf("unable to decode snaphot: %w", err)
+			}
+
+			snapshots = append(snapshots, snapshot)
+
+			return nil
+		})
+	})
+
+	return
 }
 
 //GetHostSnapshots returns all snapshots between two timestamps (inclusive)
@@ -113,6 +196,8 @@ func GetDailySnapshots(start, end time.Time) (snapshots []types.HostSnapshot, er
 			snapshots[i].EarnedRevenue = snapshots[i].EarnedRevenue.Add(snapshot.EarnedRevenue)
 			snapshots[i].PotentialRevenue = snapshots[i].PotentialRevenue.Add(snapshot.PotentialRevenue)
 			snapshots[i].BurntCollateral = snapshots[i].BurntCollateral.Add(snapshot.BurntCollateral)
+			snapshots[i].EarnedBreakdown = snapshots[i].EarnedBreakdown.Add(snapshot.EarnedBreakdown)
+			snapshots[i].PotentialBreakdown = snapshots[i].PotentialBreakdown.Add(snapshot.PotentialBreakdown)
 		}
 
 		return nil
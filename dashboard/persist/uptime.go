@@ -0,0 +1,86 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+func checkID(timestamp time.Time) []byte {
+	buf := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(buf, uint64(timestamp.UnixNano()))
+
+	return buf
+}
+
+// SaveConnectivityCheck stores the result of a connectivity check
+func SaveConnectivityCheck(check types.ConnectivityCheck) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		buf, err := json.Marshal(check)
+
+		if err != nil {
+			return fmt.Errorf("json encode: %w", err)
+		}
+
+		if err := tx.Bucket(bucketConnectivityChecks).Put(checkID(check.Timestamp), buf); err != nil {
+			return fmt.Errorf("unable to put check: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetLastConnectivityCheck returns the most recent connectivity check
+func GetLastConnectivityCheck() (check types.ConnectivityCheck, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		_, buf := tx.Bucket(bucketConnectivityChecks).Cursor().Last()
+
+		if buf == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(buf, &check); err != nil {
+			return fmt.Errorf("unable to decode check: %w", err)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// GetConnectivityChecks returns all connectivity checks between two timestamps
+// (inclusive)
+func GetConnectivityChecks(start, end time.Time) (checks []types.ConnectivityCheck, err error) {
+	if start.After(end) {
+		err = errors.New("start must be before end")
+		return
+	}
+
+	startID, endID := checkID(start), checkID(end)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketConnectivityChecks).Cursor()
+
+		for key, buf := c.Seek(startID); key != nil && bytes.Compare(key, endID) <= 0; key, buf = c.Next() {
+			var check types.ConnectivityCheck
+
+			if err := json.Unmarshal(buf, &check); err != nil {
+				return fmt.Errorf("unable to decode check: %w", err)
+			}
+
+			checks = append(checks, check)
+		}
+
+		return nil
+	})
+
+	return
+}
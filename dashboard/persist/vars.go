@@ -5,6 +5,31 @@ import "gitlab.com/NebulousLabs/bolt"
 var (
 	db *bolt.DB
 
-	bucketHostMeta      = []byte("hostmeta")
-	bucketHostSnapshots = []byte("hostsnapshots")
+	bucketHostMeta           = []byte("hostmeta")
+	bucketHostSnapshots      = []byte("hostsnapshots")
+	bucketContracts          = []byte("contracts")
+	bucketConfirmedTxns      = []byte("confirmedtxns")
+	bucketBlockTimestamp     = []byte("blocktimestamps")
+	bucketContractEvents     = []byte("contractevents")
+	bucketPendingEvents      = []byte("pendingcontractevents")
+	bucketConnectivityChecks = []byte("connectivitychecks")
+	bucketBandwidth          = []byte("bandwidth")
+	bucketBandwidthCounters  = []byte("bandwidthcounters")
+	bucketShareTokens        = []byte("sharetokens")
+	bucketAlertOccurrences   = []byte("alertoccurrences")
+
+	buckets = [][]byte{
+		bucketHostMeta,
+		bucketHostSnapshots,
+		bucketContracts,
+		bucketConfirmedTxns,
+		bucketBlockTimestamp,
+		bucketContractEvents,
+		bucketPendingEvents,
+		bucketConnectivityChecks,
+		bucketBandwidth,
+		bucketBandwidthCounters,
+		bucketShareTokens,
+		bucketAlertOccurrences,
+	}
 )
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	templateFuncs = map[string]interface{}{
+		"bytes":    formatBytes,
+		"sc":       formatCurrency,
+		"bigsc":    formatBigNumber,
+		"growth":   formatGrowth,
+		"date":     formatDate,
+		"severity": strings.Title,
+	}
+
+	markdownTemplate = template.Must(template.New("markdown").Funcs(templateFuncs).Parse(`# Host {{ .Period }} report
+
+{{ date .Start }} - {{ date .End }}
+
+## Revenue
+| | |
+|---|---|
+| Earned | {{ bigsc .EarnedRevenue }} |
+| Potential | {{ sc .PotentialRevenue }} |
+| Burnt Collateral | {{ sc .BurntCollateral }} |
+
+## Contracts
+| | |
+|---|---|
+| Active | {{ .ActiveContracts }} |
+| New | {{ .NewContracts }} |
+| Expired | {{ .ExpiredContracts }} |
+| Successful | {{ .SuccessfulContracts }} |
+| Failed | {{ .FailedContracts }} |
+
+## Storage
+| | |
+|---|---|
+| Used | {{ bytes .UsedStorage }} of {{ bytes .TotalStorage }} |
+| Growth | {{ growth .StorageGrowth }} |
+
+## Bandwidth
+| | |
+|---|---|
+| Upload | {{ bytes .UploadBandwidth }} |
+| Download | {{ bytes .DownloadBandwidth }} |
+
+## Alerts
+{{ range .Alerts }}- **{{ severity .Severity }}** {{ .Text }}
+{{ else }}No alerts during this period
+{{ end }}`))
+
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Host {{ .Period }} report</title>
+</head>
+<body style="font-family: sans-serif;">
+	<h1>Host {{ .Period }} report</h1>
+	<p>{{ date .Start }} - {{ date .End }}</p>
+	<h2>Revenue</h2>
+	<table>
+		<tr><td>Earned</td><td>{{ bigsc .EarnedRevenue }}</td></tr>
+		<tr><td>Potential</td><td>{{ sc .PotentialRevenue }}</td></tr>
+		<tr><td>Burnt Collateral</td><td>{{ sc .BurntCollateral }}</td></tr>
+	</table>
+	<h2>Contracts</h2>
+	<table>
+		<tr><td>Active</td><td>{{ .ActiveContracts }}</td></tr>
+		<tr><td>New</td><td>{{ .NewContracts }}</td></tr>
+		<tr><td>Expired</td><td>{{ .ExpiredContracts }}</td></tr>
+		<tr><td>Successful</td><td>{{ .SuccessfulContracts }}</td></tr>
+		<tr><td>Failed</td><td>{{ .FailedContracts }}</td></tr>
+	</table>
+	<h2>Storage</h2>
+	<table>
+		<tr><td>Used</td><td>{{ bytes .UsedStorage }} of {{ bytes .TotalStorage }}</td></tr>
+		<tr><td>Growth</td><td>{{ growth .StorageGrowth }}</td></tr>
+	</table>
+	<h2>Bandwidth</h2>
+	<table>
+		<tr><td>Upload</td><td>{{ bytes .UploadBandwidth }}</td></tr>
+		<tr><td>Download</td><td>{{ bytes .DownloadBandwidth }}</td></tr>
+	</table>
+	<h2>Alerts</h2>
+	{{ if .Alerts }}<ul>
+		{{ range .Alerts }}<li><strong>{{ severity .Severity }}</strong> {{ .Text }}</li>
+		{{ end }}
+	</ul>{{ else }}<p>No alerts during this period</p>{{ end }}
+</body>
+</html>
+`))
+)
+
+func formatBytes(n uint64) string {
+	const unit = 1000
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.2f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatGrowth(n int64) string {
+	if n < 0 {
+		return "-" + formatBytes(uint64(-n))
+	}
+
+	return "+" + formatBytes(uint64(n))
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("Jan 2, 2006")
+}
+
+func formatCurrency(c siatypes.Currency) string {
+	return c.HumanString()
+}
+
+func formatBigNumber(b types.BigNumber) string {
+	precision, _ := siatypes.SiacoinPrecision.Float64()
+
+	return fmt.Sprintf("%.2f SC", b.Float64()/precision)
+}
+
+// RenderMarkdown renders the report as Markdown
+func RenderMarkdown(report types.HostReport) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := markdownTemplate.Execute(&buf, report); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderHTML renders the report as HTML
+func RenderHTML(report types.HostReport) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := htmlTemplate.Execute(&buf, report); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// periodRange returns the start and end of the last complete period before
+// the timestamp
+func periodRange(period string, timestamp time.Time) (start, end time.Time) {
+	timestamp = timestamp.UTC()
+	end = time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case types.ReportWeekly:
+		// weeks start on Monday
+		end = end.AddDate(0, 0, -((int(end.Weekday()) + 6) % 7))
+		start = end.AddDate(0, 0, -7)
+	default:
+		start = end.AddDate(0, 0, -1)
+	}
+
+	return
+}
+
+func reportID(period string, start time.Time) string {
+	return fmt.Sprintf("%s-%s", period, start.Format("2006-01-02"))
+}
+
+// Build generates a report of the host's activity between start and end.
+// Alerts are the distinct alerts that were active during the period.
+func Build(period string, start, end time.Time) (report types.HostReport, err error) {
+	report = types.HostReport{
+		ID:        reportID(period, start),
+		Period:    period,
+		Start:     start,
+		End:       end,
+		Generated: time.Now().UTC(),
+	}
+
+	occurrences, err := persist.GetAlertOccurrences(start, end)
+	if err != nil {
+		return report, fmt.Errorf("get alerts: %w", err)
+	}
+
+	seen := make(map[types.HostAlert]bool)
+	for _, occurrence := range occurrences {
+		if seen[occurrence.HostAlert] {
+			continue
+		}
+
+		seen[occurrence.HostAlert] = true
+		report.Alerts = append(report.Alerts, occurrence.HostAlert)
+	}
+
+	// daily snapshots contain the totals for the day before their timestamp
+	snapshots, err := persist.GetDailySnapshots(start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return report, fmt.Errorf("get snapshots: %w", err)
+	}
+
+	for _, snapshot := range snapshots {
+		if !snapshot.Timestamp.After(start) || snapshot.Timestamp.After(end) {
+			continue
+		}
+
+		report.ActiveContracts = snapshot.ActiveContracts
+		report.NewContracts += snapshot.NewContracts
+		report.ExpiredContracts += snapshot.ExpiredContracts
+		report.SuccessfulContracts += snapshot.SuccessfulContracts
+		report.FailedContracts += snapshot.FailedContracts
+		report.EarnedRevenue = report.EarnedRevenue.Add(snapshot.EarnedRevenue)
+		report.PotentialRevenue = report.PotentialRevenue.Add(snapshot.PotentialRevenue)
+		report.BurntCollateral = report.BurntCollateral.Add(snapshot.BurntCollateral)
+	}
+
+	metadata, err := persist.GetHostMetadata(start, end)
+	if err != nil {
+		return report, fmt.Errorf("get metadata: %w", err)
+	}
+
+	if len(metadata) == 0 {
+		return report, nil
+	}
+
+	first, last := metadata[0], metadata[len(metadata)-1]
+
+	report.UsedStorage = last.UsedStorage
+	report.TotalStorage = last.TotalStorage
+	report.StorageGrowth = int64(last.UsedStorage) - int64(first.UsedStorage)
+
+	if last.UploadBandwidth >= first.UploadBandwidth {
+		report.UploadBandwidth = last.UploadBandwidth - first.UploadBandwidth
+	}
+
+	if last.DownloadBandwidth >= first.DownloadBandwidth {
+		report.DownloadBandwidth = last.DownloadBandwidth - first.DownloadBandwidth
+	}
+
+	return report, nil
+}
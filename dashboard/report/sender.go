@@ -0,0 +1,57 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+type (
+	// A Sender delivers generated reports
+	Sender interface {
+		Send(report types.HostReport, html, markdown []byte) error
+	}
+
+	// WebhookSender posts generated reports as JSON to a URL
+	WebhookSender struct {
+		URL string
+	}
+
+	webhookPayload struct {
+		Report   types.HostReport `json:"report"`
+		Markdown string           `json:"markdown"`
+	}
+)
+
+var webhookClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// Send posts the report and its Markdown rendering to the webhook URL
+func (ws WebhookSender) Send(report types.HostReport, html, markdown []byte) error {
+	buf, err := json.Marshal(webhookPayload{
+		Report:   report,
+		Markdown: string(markdown),
+	})
+	if err != nil {
+		return fmt.Errorf("json encode: %w", err)
+	}
+
+	resp, err := webhookClient.Post(ws.URL, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/logging"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// reportDelay is how long after a period ends before its report is generated,
+// giving the contract sync time to record the end of the period
+const reportDelay = time.Hour
+
+var (
+	reportDir string
+	senders   []Sender
+	logger    *logging.Logger
+)
+
+// Options options when generating reports
+type Options struct {
+	// DataPath the directory reports are stored under
+	DataPath string
+	// Senders deliver each generated report
+	Senders []Sender
+	// Log the logger for report errors
+	Log *logging.Logger
+}
+
+// generate builds, stores, and sends the last complete report for the period
+// if it has not been generated yet
+func generate(period string, now time.Time) error {
+	start, end := periodRange(period, now)
+
+	if now.Sub(end) < reportDelay || reportExists(reportID(period, start)) {
+		return nil
+	}
+
+	report, err := Build(period, start, end)
+	if err != nil {
+		return fmt.Errorf("build %s report: %w", period, err)
+	}
+
+	html, markdown, err := save(report)
+	if err != nil {
+		return fmt.Errorf("save %s report: %w", period, err)
+	}
+
+	for _, sender := range senders {
+		if err := sender.Send(report, html, markdown); err != nil {
+			logger.Warn("unable to send report", "period", period, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func generateReports() {
+	for {
+		now := time.Now()
+
+		for _, period := range []string{types.ReportDaily, types.ReportWeekly} {
+			if err := generate(period, now); err != nil {
+				logger.Error("unable to generate report", "period", period, "error", err)
+			}
+		}
+
+		time.Sleep(now.Add(time.Hour).Truncate(time.Hour).Sub(now))
+	}
+}
+
+// Start begins generating daily and weekly reports
+func Start(opts Options) error {
+	reportDir = filepath.Join(opts.DataPath, "reports")
+	senders = opts.Senders
+	logger = opts.Log
+
+	if err := os.MkdirAll(reportDir, 0750); err != nil {
+		return fmt.Errorf("create report directory: %w", err)
+	}
+
+	go generateReports()
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+const (
+	// FormatJSON the report's raw data
+	FormatJSON = "json"
+	// FormatHTML the report rendered as HTML
+	FormatHTML = "html"
+	// FormatMarkdown the report rendered as Markdown
+	FormatMarkdown = "md"
+)
+
+// ErrNotFound returned if a report does not exist
+var ErrNotFound = errors.New("report not found")
+
+func reportPath(id, format string) string {
+	return filepath.Join(reportDir, fmt.Sprintf("%s.%s", id, format))
+}
+
+// validID returns true if the id can be safely used as a file name
+func validID(id string) bool {
+	return len(id) > 0 && !strings.ContainsAny(id, `/\.`)
+}
+
+func reportExists(id string) bool {
+	_, err := os.Stat(reportPath(id, FormatJSON))
+	return err == nil
+}
+
+// save renders and stores the report in every format
+func save(report types.HostReport) (html, markdown []byte, err error) {
+	buf, err := json.Marshal(report)
+	if err != nil {
+		return nil, nil, fmt.Errorf("json encode: %w", err)
+	}
+
+	html, err = RenderHTML(report)
+	if err != nil {
+		return nil, nil, fmt.Errorf("render html: %w", err)
+	}
+
+	markdown, err = RenderMarkdown(report)
+	if err != nil {
+		return nil, nil, fmt.Errorf("render markdown: %w", err)
+	}
+
+	// the json is written last since it marks the report as generated
+	if err := ioutil.WriteFile(reportPath(report.ID, FormatHTML), html, 0640); err != nil {
+		return nil, nil, fmt.Errorf("write html: %w", err)
+	} else if err := ioutil.WriteFile(reportPath(report.ID, FormatMarkdown), markdown, 0640); err != nil {
+		return nil, nil, fmt.Errorf("write markdown: %w", err)
+	} else if err := ioutil.WriteFile(reportPath(report.ID, FormatJSON), buf, 0640); err != nil {
+		return nil, nil, fmt.Errorf("write json: %w", err)
+	}
+
+	return
+}
+
+// GetReports returns the stored reports ordered from newest to oldest
+func GetReports() (reports []types.HostReport, err error) {
+	matches, err := filepath.Glob(filepath.Join(reportDir, "*."+FormatJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, match := range matches {
+		var report types.HostReport
+
+		buf, err := ioutil.ReadFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("read report: %w", err)
+		}
+
+		if err := json.Unmarshal(buf, &report); err != nil {
+			return nil, fmt.Errorf("decode report %s: %w", filepath.Base(match), err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Start.After(reports[j].Start)
+	})
+
+	return
+}
+
+// GetReport returns a stored report in the specified format
+func GetReport(id, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON, FormatHTML, FormatMarkdown:
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+
+	buf, err := ioutil.ReadFile(reportPath(id, format))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return buf, err
+}
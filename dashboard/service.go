@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/service"
+)
+
+// defaultServiceName the name the dashboard's service is installed as
+const defaultServiceName = "sia-host-dashboard"
+
+// serviceAction an action of the service command and the message printed
+// when it succeeds
+type serviceAction struct {
+	fn   func(service.Config) error
+	done string
+}
+
+// serviceActions the actions of the service command
+var serviceActions = map[string]serviceAction{
+	"install":   {service.Install, "installed"},
+	"uninstall": {service.Uninstall, "uninstalled"},
+	"start":     {service.Start, "started"},
+	"stop":      {service.Stop, "stopped"},
+}
+
+// serviceConfig returns the configuration of the installed service. The
+// dashboard is run from the current executable with an absolute data path so
+// it does not depend on the service manager's working directory.
+func serviceConfig(name, dataPath string, user bool, runAs string, args []string) (service.Config, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return service.Config{}, fmt.Errorf("get executable: %w", err)
+	}
+
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return service.Config{}, fmt.Errorf("resolve executable: %w", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return service.Config{}, fmt.Errorf("get working directory: %w", err)
+	}
+
+	dataPath, err = filepath.Abs(dataPath)
+	if err != nil {
+		return service.Config{}, fmt.Errorf("resolve data path: %w", err)
+	}
+
+	for _, arg := range args {
+		if arg == "--data-path" || arg == "-data-path" || strings.HasPrefix(arg, "--data-path=") || strings.HasPrefix(arg, "-data-path=") {
+			return service.Config{}, errors.New("set --data-path before --")
+		}
+	}
+
+	return service.Config{
+		Name:             name,
+		DisplayName:      "Sia Host Dashboard",
+		Description:      "Sia Host Dashboard",
+		Executable:       exe,
+		Args:             append([]string{"--skip-browser", "--data-path", dataPath}, args...),
+		WorkingDirectory: wd,
+		User:             user,
+		RunAs:            runAs,
+	}, nil
+}
+
+func serviceCommand(args []string) error {
+	var name, dataPath, runAs string
+	var user bool
+
+	fs := flag.NewFlagSet("service", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), `Usage: dashboard service install|uninstall|start|stop [flags] [-- dashboard flags]
+
+Installs the dashboard as a systemd service on Linux, a launchd service on
+macOS, or a Windows service. Installed services start at boot. Flags after --
+are passed to the dashboard when the service starts.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&name, "name", defaultServiceName, "the name of the service")
+	fs.StringVar(&dataPath, "data-path", "data", "the data path the service uses")
+	fs.BoolVar(&user, "user", false, "manages a service for the current user instead of a system service")
+	fs.StringVar(&runAs, "run-as", "", "the user a system service runs as. Defaults to root")
+
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	action, exists := serviceActions[args[0]]
+	if !exists {
+		return fmt.Errorf("unknown service action %q, must be install, uninstall, start, or stop", args[0])
+	}
+
+	// flags after -- are passed to the dashboard
+	flags, dashboardArgs := args[1:], []string(nil)
+	for i, arg := range flags {
+		if arg == "--" {
+			flags, dashboardArgs = flags[:i], flags[i+1:]
+			break
+		}
+	}
+
+	if err := fs.Parse(flags); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	} else if len(dashboardArgs) != 0 && args[0] != "install" {
+		return errors.New("dashboard flags can only be set when installing")
+	}
+
+	cfg, err := serviceConfig(name, dataPath, user, runAs, dashboardArgs)
+	if err != nil {
+		return err
+	}
+
+	if err := action.fn(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service %s %s\n", name, action.done)
+
+	return nil
+}
+
+// listenForStop starts listening for stop requests from the user or the
+// service manager. The initial sync cannot be interrupted gracefully, a stop
+// request before started is closed exits immediately.
+func listenForStop() (stop <-chan struct{}, started chan struct{}) {
+	stop = service.Listen(defaultServiceName)
+	started = make(chan struct{})
+
+	go func() {
+		select {
+		case <-stop:
+			writeLine("Shutting down")
+			service.Exit()
+			os.Exit(0)
+		case <-started:
+		}
+	}()
+
+	return
+}
+
+// waitForStop notifies the service manager that the dashboard is ready,
+// notifies the watchdog while the database is responsive, and blocks until
+// the dashboard is asked to stop
+func waitForStop(stop <-chan struct{}) {
+	if err := service.Ready(); err != nil {
+		logger.Warn("unable to notify service manager", "error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go service.Watchdog(ctx, func() error {
+		if _, err := persist.GetLastMetadata(); err != nil {
+			logger.Error("health check failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+
+	<-stop
+
+	writeLine("Shutting down")
+
+	if err := service.Stopping(); err != nil {
+		logger.Warn("unable to notify service manager", "error", err)
+	}
+}
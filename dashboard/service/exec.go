@@ -0,0 +1,25 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package service
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// run runs the command and includes its output in the returned error
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) == 0 {
+			return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+		}
+
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// plistTemplate the launchd property list. The service is started at load
+// and restarted if it exits with an error.
+var plistTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var buf bytes.Buffer
+		err := xml.EscapeText(&buf, []byte(s))
+		return buf.String(), err
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{ xml .Label }}</string>
+	<key>ProgramArguments</key>
+	<array>
+	{{- range .Args }}
+		<string>{{ xml . }}</string>
+	{{- end }}
+	</array>
+	{{- if .WorkingDirectory }}
+	<key>WorkingDirectory</key>
+	<string>{{ xml .WorkingDirectory }}</string>
+	{{- end }}
+	{{- if .RunAs }}
+	<key>UserName</key>
+	<string>{{ xml .RunAs }}</string>
+	{{- end }}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>10</integer>
+</dict>
+</plist>
+`))
+
+// label returns the service's launchd label
+func label(cfg Config) string {
+	return "com.siacentral." + cfg.Name
+}
+
+// plistPath returns the path of the service's property list. User services
+// are agents, system services are daemons.
+func plistPath(cfg Config) (string, error) {
+	if !cfg.User {
+		return filepath.Join("/Library/LaunchDaemons", label(cfg)+".plist"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home dir: %w", err)
+	}
+
+	return filepath.Join(home, "Library", "LaunchAgents", label(cfg)+".plist"), nil
+}
+
+func checkPermissions(cfg Config) error {
+	if cfg.User {
+		if len(cfg.RunAs) != 0 {
+			return errors.New("user services cannot run as another user")
+		}
+
+		return nil
+	}
+
+	if os.Geteuid() != 0 {
+		return errors.New("system services must be managed as root, use --user to manage a user service")
+	}
+
+	return nil
+}
+
+// Install writes the service's launchd property list and loads it. The
+// service is started immediately and at boot or login.
+func Install(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	path, err := plistPath(cfg)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = plistTemplate.Execute(&buf, map[string]interface{}{
+		"Label":            label(cfg),
+		"Args":             append([]string{cfg.Executable}, cfg.Args...),
+		"WorkingDirectory": cfg.WorkingDirectory,
+		"RunAs":            cfg.RunAs,
+	})
+	if err != nil {
+		return fmt.Errorf("render plist: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create plist directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write plist: %w", err)
+	}
+
+	return run("launchctl", "load", "-w", path)
+}
+
+// Uninstall unloads the service and removes its property list
+func Uninstall(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	path, err := plistPath(cfg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service %s is not installed: %w", cfg.Name, err)
+	}
+
+	if err := run("launchctl", "unload", "-w", path); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove plist: %w", err)
+	}
+
+	return nil
+}
+
+// Start starts the installed service
+func Start(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	return run("launchctl", "start", label(cfg))
+}
+
+// Stop stops the running service. The service is not restarted because it
+// exits successfully.
+func Stop(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	return run("launchctl", "stop", label(cfg))
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// unitTemplate the systemd unit. The service notifies systemd when it is
+// ready and while it is healthy. Startup is not limited, the initial sync of
+// a large host can take a long time.
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description={{ .Description }}
+Wants=network-online.target
+After=network-online.target siad.service
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart={{ .ExecStart }}
+{{- if .WorkingDirectory }}
+WorkingDirectory={{ .WorkingDirectory }}
+{{- end }}
+{{- if .RunAs }}
+User={{ .RunAs }}
+{{- end }}
+Restart=on-failure
+RestartSec=10
+TimeoutStartSec=infinity
+TimeoutStopSec=30
+WatchdogSec=60
+
+[Install]
+WantedBy={{ .WantedBy }}
+`))
+
+// unitPath returns the path of the service's unit file
+func unitPath(cfg Config) (string, error) {
+	if !cfg.User {
+		return filepath.Join("/etc/systemd/system", cfg.Name+".service"), nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("get config dir: %w", err)
+	}
+
+	return filepath.Join(dir, "systemd", "user", cfg.Name+".service"), nil
+}
+
+// systemctl runs systemctl for the system or user service manager
+func systemctl(cfg Config, args ...string) error {
+	if cfg.User {
+		args = append([]string{"--user"}, args...)
+	}
+
+	return run("systemctl", args...)
+}
+
+func checkPermissions(cfg Config) error {
+	if cfg.User {
+		if len(cfg.RunAs) != 0 {
+			return errors.New("user services cannot run as another user")
+		}
+
+		return nil
+	}
+
+	if os.Geteuid() != 0 {
+		return errors.New("system services must be managed as root, use --user to manage a user service")
+	}
+
+	return nil
+}
+
+// Install writes the service's systemd unit and enables it to start at boot
+func Install(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	path, err := unitPath(cfg)
+	if err != nil {
+		return err
+	}
+
+	wantedBy := "multi-user.target"
+	if cfg.User {
+		wantedBy = "default.target"
+	}
+
+	// % starts a specifier in unit files
+	escape := strings.NewReplacer("%", "%%").Replace
+
+	var buf bytes.Buffer
+	err = unitTemplate.Execute(&buf, map[string]string{
+		"Description":      escape(cfg.Description),
+		"ExecStart":        escape(quoteArgs(append([]string{cfg.Executable}, cfg.Args...))),
+		"WorkingDirectory": escape(cfg.WorkingDirectory),
+		"RunAs":            cfg.RunAs,
+		"WantedBy":         wantedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("render unit: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create unit directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write unit: %w", err)
+	}
+
+	if err := systemctl(cfg, "daemon-reload"); err != nil {
+		return err
+	}
+
+	return systemctl(cfg, "enable", cfg.Name)
+}
+
+// Uninstall stops and disables the service and removes its systemd unit
+func Uninstall(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	path, err := unitPath(cfg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service %s is not installed: %w", cfg.Name, err)
+	}
+
+	if err := systemctl(cfg, "disable", "--now", cfg.Name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove unit: %w", err)
+	}
+
+	return systemctl(cfg, "daemon-reload")
+}
+
+// Start starts the installed service
+func Start(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	return systemctl(cfg, "start", cfg.Name)
+}
+
+// Stop stops the running service
+func Stop(cfg Config) error {
+	if err := checkPermissions(cfg); err != nil {
+		return err
+	}
+
+	return systemctl(cfg, "stop", cfg.Name)
+}
+
+// quoteArgs quotes arguments containing whitespace or quotes for the
+// service's command line
+func quoteArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		if len(arg) != 0 && !strings.ContainsAny(arg, " \t\n\"'\\") {
+			quoted = append(quoted, arg)
+			continue
+		}
+
+		quoted = append(quoted, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg)+`"`)
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,24 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package service
+
+// Install returns ErrUnsupported
+func Install(cfg Config) error {
+	return ErrUnsupported
+}
+
+// Uninstall returns ErrUnsupported
+func Uninstall(cfg Config) error {
+	return ErrUnsupported
+}
+
+// Start returns ErrUnsupported
+func Start(cfg Config) error {
+	return ErrUnsupported
+}
+
+// Stop returns ErrUnsupported
+func Stop(cfg Config) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func checkConfig(cfg Config) error {
+	if cfg.User {
+		return errors.New("user services are not supported on Windows")
+	} else if len(cfg.RunAs) != 0 {
+		return errors.New("running as another user is not supported on Windows")
+	}
+
+	return nil
+}
+
+// openService connects to the service manager and opens the installed
+// service
+func openService(name string) (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to service manager: %w", err)
+	}
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		_ = m.Disconnect()
+		return nil, nil, fmt.Errorf("service %s is not installed: %w", name, err)
+	}
+
+	return m, s, nil
+}
+
+// closeService closes the service and disconnects from the service manager.
+// The handles are released when the process exits, errors are ignored.
+func closeService(m *mgr.Mgr, s *mgr.Service) {
+	_ = s.Close()
+	_ = m.Disconnect()
+}
+
+// waitForState waits for the service to reach the state
+func waitForState(s *mgr.Service, state svc.State, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		status, err := s.Query()
+		if err != nil {
+			return fmt.Errorf("query service: %w", err)
+		} else if status.State == state {
+			return nil
+		} else if time.Now().After(deadline) {
+			return errors.New("timed out waiting for the service")
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// Install registers the service with the service manager. The service starts
+// automatically at boot and is restarted if it fails.
+func Install(cfg Config) error {
+	if err := checkConfig(cfg); err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer func() {
+		_ = m.Disconnect()
+	}()
+
+	if s, err := m.OpenService(cfg.Name); err == nil {
+		_ = s.Close()
+		return fmt.Errorf("service %s is already installed", cfg.Name)
+	}
+
+	s, err := m.CreateService(cfg.Name, cfg.Executable, mgr.Config{
+		DisplayName:      cfg.DisplayName,
+		Description:      cfg.Description,
+		StartType:        mgr.StartAutomatic,
+		DelayedAutoStart: true,
+	}, cfg.Args...)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+
+	err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+	}, uint32((24 * time.Hour).Seconds()))
+	if err != nil {
+		return fmt.Errorf("set recovery actions: %w", err)
+	}
+
+	return nil
+}
+
+// Uninstall stops the service and removes it from the service manager
+func Uninstall(cfg Config) error {
+	if err := checkConfig(cfg); err != nil {
+		return err
+	}
+
+	m, s, err := openService(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer closeService(m, s)
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		if _, err := s.Control(svc.Stop); err != nil {
+			return fmt.Errorf("stop service: %w", err)
+		}
+
+		if err := waitForState(s, svc.Stopped, 30*time.Second); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+
+	return nil
+}
+
+// Start starts the installed service
+func Start(cfg Config) error {
+	if err := checkConfig(cfg); err != nil {
+		return err
+	}
+
+	m, s, err := openService(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer closeService(m, s)
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+
+	return nil
+}
+
+// Stop stops the running service and waits for it to exit
+func Stop(cfg Config) error {
+	if err := checkConfig(cfg); err != nil {
+		return err
+	}
+
+	m, s, err := openService(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer closeService(m, s)
+
+	if _, err := s.Control(svc.Stop); err != nil {
+		return fmt.Errorf("stop service: %w", err)
+	}
+
+	return waitForState(s, svc.Stopped, 30*time.Second)
+}
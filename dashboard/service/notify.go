@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notify sends the state to systemd's notification socket. Does nothing if
+// the process was not started by systemd with Type=notify.
+func Notify(state string) error {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if len(name) == 0 {
+		return nil
+	}
+
+	// abstract sockets are prefixed with @ in the environment
+	if name[0] == '@' {
+		name = "\x00" + name[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("dial notify socket: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("write notify socket: %w", err)
+	}
+
+	return nil
+}
+
+// WatchdogInterval returns how often the watchdog must be notified, half of
+// the service's WatchdogSec. Returns 0 if the watchdog is not enabled for the
+// process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseUint(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec == 0 {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); len(pid) != 0 && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// Watchdog notifies systemd's watchdog until the context is cancelled. The
+// watchdog is only notified when check succeeds, systemd restarts the service
+// if it is not notified in time. Returns immediately if the watchdog is not
+// enabled.
+func Watchdog(ctx context.Context, check func() error) {
+	interval := WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		if err := check(); err != nil {
+			continue
+		}
+
+		_ = Notify("WATCHDOG=1")
+	}
+}
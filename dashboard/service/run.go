@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package service
+
+// listen on Windows starts the service control dispatcher. On other systems,
+// stop requests are received as signals.
+func listen(name string, stop chan struct{}) bool {
+	return false
+}
+
+func exit() {}
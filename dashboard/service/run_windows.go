@@ -0,0 +1,84 @@
+package service
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+type handler struct {
+	stop   chan struct{}
+	exited chan struct{}
+}
+
+var (
+	// exited closed by Exit when the process has finished shutting down
+	exited = make(chan struct{})
+	// dispatched closed when the service control dispatcher returns
+	dispatched = make(chan struct{})
+	running    bool
+)
+
+// Execute implements svc.Handler. The service is reported as running
+// immediately, the initial sync can take longer than the service manager
+// waits for a pending start.
+func (h *handler) Execute(args []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	s <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case <-h.exited:
+			return false, 0
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				s <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				s <- svc.Status{State: svc.StopPending, WaitHint: 10000}
+				close(h.stop)
+				<-h.exited
+				return false, 0
+			}
+		}
+	}
+}
+
+// listen starts the service control dispatcher if the process was started by
+// the service manager
+func listen(name string, stop chan struct{}) bool {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false
+	}
+
+	running = true
+	go func() {
+		defer close(dispatched)
+
+		if err := svc.Run(name, &handler{stop: stop, exited: exited}); err != nil {
+			// the dispatcher could not connect, stop instead of running
+			// without the service manager
+			select {
+			case <-stop:
+			default:
+				close(stop)
+			}
+		}
+	}()
+
+	return true
+}
+
+// exit reports the service as stopped and waits for the dispatcher to return
+func exit() {
+	if !running {
+		return
+	}
+
+	close(exited)
+
+	select {
+	case <-dispatched:
+	case <-time.After(5 * time.Second):
+	}
+}
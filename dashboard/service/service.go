@@ -0,0 +1,75 @@
+// Package service installs the dashboard as a system service and integrates
+// with the service manager while it is running
+package service
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type (
+	// Config the configuration of the installed service
+	Config struct {
+		// Name the name the service is registered under
+		Name        string
+		DisplayName string
+		Description string
+		// Executable the absolute path of the binary to run
+		Executable string
+		// Args the arguments the binary is run with
+		Args []string
+		// WorkingDirectory the directory the service is started in
+		WorkingDirectory string
+		// User installs the service for the current user instead of system
+		// wide. Not supported on Windows.
+		User bool
+		// RunAs the account a system service runs as. Defaults to root. Not
+		// supported on Windows.
+		RunAs string
+	}
+)
+
+// ErrUnsupported returned when services are not supported on the current
+// platform
+var ErrUnsupported = errors.New("services are not supported on this platform")
+
+// stopChan closed when the process has been asked to stop
+var stopChan = make(chan struct{})
+
+// Listen starts listening for stop requests from the user or the service
+// manager and returns a channel that is closed when one is received. On
+// Windows the service control dispatcher is started when the process was
+// started by the service manager.
+func Listen(name string) <-chan struct{} {
+	if listen(name, stopChan) {
+		return stopChan
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		close(stopChan)
+	}()
+
+	return stopChan
+}
+
+// Ready notifies the service manager that the process has started
+func Ready() error {
+	return Notify("READY=1")
+}
+
+// Stopping notifies the service manager that the process is shutting down
+func Stopping() error {
+	return Notify("STOPPING=1")
+}
+
+// Exit reports the process has stopped to the service manager. Called after
+// the process has finished shutting down.
+func Exit() {
+	exit()
+}
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// alertRetention how long alert occurrences are kept for reports
+const alertRetention = 90 * 24 * time.Hour
+
+var (
+	// alertHour the hour the recorded alerts were stored in
+	alertHour time.Time
+	// recordedAlerts the alerts already stored for the current hour
+	recordedAlerts = make(map[types.HostAlert]bool)
+)
+
+// recordAlerts stores the active alerts so reports include the alerts that
+// were raised during their period. Each alert is stored once per hour, old
+// occurrences are pruned when the hour changes.
+func recordAlerts() {
+	now := time.Now().UTC()
+
+	if hour := now.Truncate(time.Hour); !hour.Equal(alertHour) {
+		alertHour = hour
+		recordedAlerts = make(map[types.HostAlert]bool)
+
+		if pruned, err := persist.PruneAlertOccurrences(now.Add(-alertRetention)); err != nil {
+			logger.Error("unable to prune alerts", "error", err)
+		} else if pruned > 0 {
+			logger.Debug("pruned alerts", "count", pruned)
+		}
+	}
+
+	var occurrences []types.AlertOccurrence
+	for _, alert := range cache.GetAlerts() {
+		if recordedAlerts[alert] {
+			continue
+		}
+
+		occurrences = append(occurrences, types.AlertOccurrence{
+			HostAlert: alert,
+			Timestamp: now,
+		})
+	}
+
+	if len(occurrences) == 0 {
+		return
+	}
+
+	if err := persist.SaveAlertOccurrences(occurrences); err != nil {
+		logger.Error("unable to save alerts", "error", err)
+		return
+	}
+
+	for _, occurrence := range occurrences {
+		recordedAlerts[occurrence.HostAlert] = true
+	}
+}
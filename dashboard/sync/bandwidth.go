@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/Sia/node/api"
+)
+
+var (
+	counters    types.BandwidthCounters
+	bandwidthMu sync.Mutex
+)
+
+// counterDelta returns the number of bytes counted since the last reading. If
+// the counter decreased it was reset and every byte it counted was transferred
+// after the last reading.
+func counterDelta(current, last uint64) uint64 {
+	if current < last {
+		return current
+	}
+
+	return current - last
+}
+
+// bandwidthDelta returns the number of bytes transferred since the last
+// reading. Sia resets its counters when it restarts, a changed start time
+// means every byte counted was transferred after the last reading.
+func bandwidthDelta(bw api.GatewayBandwidthGET) (upload, download uint64) {
+	switch {
+	case counters.StartTime.IsZero():
+		// nothing to compare the first reading against
+		return 0, 0
+	case !bw.StartTime.Equal(counters.StartTime):
+		return bw.Upload, bw.Download
+	}
+
+	return counterDelta(bw.Upload, counters.LastUpload), counterDelta(bw.Download, counters.LastDownload)
+}
+
+// getBandwidthUsage returns the total bandwidth used by the host. The last
+// totals are returned if Sia's counters cannot be read.
+func getBandwidthUsage() (upload, download uint64) {
+	bandwidthMu.Lock()
+	defer bandwidthMu.Unlock()
+
+	bw, err := apiClient.HostBandwidthGet()
+
+	if err != nil {
+		logger.Warn("unable to retrieve bandwidth", "error", err)
+		return counters.TotalUpload, counters.TotalDownload
+	}
+
+	dUp, dDown := bandwidthDelta(bw)
+
+	counters.TotalUpload += dUp
+	counters.TotalDownload += dDown
+	counters.LastUpload = bw.Upload
+	counters.LastDownload = bw.Download
+	counters.StartTime = bw.StartTime
+	counters.Timestamp = time.Now().UTC()
+
+	if err := persist.SaveBandwidthReading(counters, types.BandwidthUsage{
+		Upload:    dUp,
+		Download:  dDown,
+		Timestamp: counters.Timestamp,
+	}); err != nil {
+		logger.Warn("unable to save bandwidth usage", "error", err)
+	}
+
+	return counters.TotalUpload, counters.TotalDownload
+}
+
+// initBandwidthCounters loads the bandwidth counters from the database. The
+// stored start time is compared to Sia's on the next reading so bytes
+// transferred while the dashboard was stopped are counted once. If the
+// counters have not been stored yet the totals are loaded from the last
+// metadata.
+func initBandwidthCounters() {
+	bandwidthMu.Lock()
+	defer bandwidthMu.Unlock()
+
+	stored, exists, err := persist.GetBandwidthCounters()
+	if err != nil {
+		logger.Warn("unable to load bandwidth counters", "error", err)
+	} else if exists {
+		counters = stored
+		return
+	}
+
+	meta, err := persist.GetLastMetadata()
+	if err != nil {
+		logger.Warn("unable to load bandwidth", "error", err)
+		return
+	}
+
+	counters.TotalUpload = meta.UploadBandwidth
+	counters.TotalDownload = meta.DownloadBandwidth
+}
@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/forecast"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// capacityHistory is the amount of metadata history used to fit storage growth
+const capacityHistory = 30 * 24 * time.Hour
+
+func addCapacityAlert(name string, estimate types.CapacityEstimate) {
+	if estimate.DaysUntilFull < 0 || estimate.DaysUntilFull >= float64(options.FullAlertDays) {
+		return
+	}
+
+	severity := "warning"
+	if estimate.DaysUntilFull < float64(options.FullAlertDays)/2 {
+		severity = "severe"
+	}
+
+	cache.AddAlert(AlertStorageCapacity, types.HostAlert{
+		Severity: severity,
+		Text:     fmt.Sprintf("%s projected to be full in %d days, add more storage.", name, int(math.Ceil(estimate.DaysUntilFull))),
+		Type:     "storage",
+	})
+}
+
+// syncCapacityPlan projects when the host and its storage folders will be full
+// from the stored metadata
+func syncCapacityPlan() {
+	current := time.Now()
+
+	metadata, err := persist.GetHostMetadata(current.Add(-capacityHistory), current)
+	if err != nil {
+		logger.Error("unable to get metadata", "error", err)
+		return
+	}
+
+	plan := forecast.PlanCapacity(metadata, current)
+	cache.SetCapacityPlan(plan)
+
+	cache.ClearAlerts(AlertStorageCapacity)
+
+	if options.FullAlertDays <= 0 {
+		return
+	}
+
+	addCapacityAlert("Storage", plan.Host)
+
+	for _, folder := range plan.Folders {
+		addCapacityAlert(fmt.Sprintf("Folder %s", folder.Path), folder)
+	}
+}
@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/node/api"
+)
+
+const (
+	// ConnectivityRemote checks the host's connectivity using the Sia Central
+	// API
+	ConnectivityRemote = "remote"
+	// ConnectivityLocal checks the host's connectivity by connecting to the
+	// host directly
+	ConnectivityLocal = "local"
+	// ConnectivityBoth checks the host's connectivity using both the local
+	// and remote checks
+	ConnectivityBoth = "both"
+
+	// maxSettingsLen is the maximum size of the host's settings response
+	maxSettingsLen = 4096
+)
+
+// dialTimeout is the maximum time to wait when connecting to the host
+var dialTimeout = 30 * time.Second
+
+func addConnectionAlert(severity, format string, args ...interface{}) {
+	cache.AddAlert(AlertConnectionStatus, types.HostAlert{
+		Severity: severity,
+		Text:     fmt.Sprintf(format, args...),
+		Type:     "connection",
+	})
+}
+
+// requestSettings performs the settings RPC handshake with the host
+func requestSettings(conn net.Conn, host api.HostGET) (settings modules.HostExternalSettings, err error) {
+	if err = conn.SetDeadline(time.Now().Add(dialTimeout)); err != nil {
+		return
+	}
+
+	s, _, err := modules.NewRenterSession(conn, host.PublicKey)
+	if err != nil {
+		return settings, fmt.Errorf("open session: %w", err)
+	}
+	defer func() {
+		// the settings have already been read, if the exit request fails
+		// the host times out the session
+		_ = s.WriteRequest(modules.RPCLoopExit, nil)
+	}()
+
+	if err = s.WriteRequest(modules.RPCLoopSettings, nil); err != nil {
+		return settings, fmt.Errorf("write settings request: %w", err)
+	}
+
+	var resp modules.LoopSettingsResponse
+	if err = s.ReadResponse(&resp, maxSettingsLen); err != nil {
+		return settings, fmt.Errorf("read settings response: %w", err)
+	}
+
+	if err = json.Unmarshal(resp.Settings, &settings); err != nil {
+		return settings, fmt.Errorf("decode settings: %w", err)
+	}
+
+	return
+}
+
+// checkLocalConnectivity checks the host's connectivity by resolving its
+// announced address, connecting to the host and SiaMux ports, and requesting
+// the host's settings. Problems are added as alerts.
+func checkLocalConnectivity(host api.HostGET, check *types.ConnectivityCheck) {
+	netaddress := host.ExternalSettings.NetAddress
+	hostname := netaddress.Host()
+
+	if _, err := net.LookupHost(hostname); err != nil {
+		addConnectionAlert("severe", "Unable to resolve %s. Check your DNS settings.", hostname)
+		return
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", string(netaddress), dialTimeout)
+	if err != nil {
+		addConnectionAlert("severe", "Unable to connect to host port %s. Check your port forwarding.", netaddress.Port())
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	check.Latency = uint64(time.Since(start).Milliseconds())
+
+	settings, err := requestSettings(conn, host)
+	if err != nil {
+		addConnectionAlert("severe", "Host did not respond to settings request: %s", err)
+		return
+	}
+
+	check.Reachable = true
+	check.AcceptingContracts = settings.AcceptingContracts
+
+	muxAddr := net.JoinHostPort(hostname, host.ExternalSettings.SiaMuxPort)
+	muxConn, err := net.DialTimeout("tcp", muxAddr, dialTimeout)
+	if err != nil {
+		addConnectionAlert("severe", "Unable to connect to SiaMux port %s. Check your port forwarding.", host.ExternalSettings.SiaMuxPort)
+		return
+	}
+	_ = muxConn.Close()
+}
+
+// checkRemoteConnectivity checks the host's connectivity using the Sia Central
+// API. An error is returned if the check could not be performed.
+func checkRemoteConnectivity(netaddress string, check *types.ConnectivityCheck) error {
+	report, err := siacentralapi.GetHostConnectivity(netaddress)
+	if err != nil {
+		return err
+	}
+
+	check.Reachable = report.Connected
+	check.AcceptingContracts = report.Connected && report.Settings.AcceptingContracts
+	check.Latency = report.Latency
+
+	for _, err := range report.Errors {
+		cache.AddAlert(AlertConnectionStatus, types.HostAlert{
+			Severity: err.Severity,
+			Text:     err.Message,
+			Type:     err.Type,
+		})
+	}
+
+	return nil
+}
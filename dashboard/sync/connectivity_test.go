@@ -0,0 +1,287 @@
+package sync
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/node/api"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/encoding"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// rhpHost a stand-in for the host's RHP2 port. handle is called with each
+// connection.
+type rhpHost struct {
+	sk     crypto.SecretKey
+	pk     crypto.PublicKey
+	l      net.Listener
+	handle func(*rhpHost, net.Conn) error
+}
+
+// serveSettings performs the renter-host handshake and responds to the
+// settings request
+func serveSettings(settings modules.HostExternalSettings) func(*rhpHost, net.Conn) error {
+	return func(h *rhpHost, conn net.Conn) error {
+		aead, err := h.handshake(conn)
+		if err != nil {
+			return err
+		}
+
+		id, err := modules.ReadRPCID(conn, aead)
+		if err != nil {
+			return err
+		} else if id != modules.RPCLoopSettings {
+			return errors.New("unexpected rpc " + id.String())
+		}
+
+		buf, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+
+		return modules.WriteRPCResponse(conn, aead, modules.LoopSettingsResponse{Settings: buf}, nil)
+	}
+}
+
+// serveError performs the renter-host handshake and responds to the settings
+// request with an error
+func serveError(h *rhpHost, conn net.Conn) error {
+	aead, err := h.handshake(conn)
+	if err != nil {
+		return err
+	}
+
+	if _, err := modules.ReadRPCID(conn, aead); err != nil {
+		return err
+	}
+
+	return modules.WriteRPCResponse(conn, aead, nil, errors.New("settings unavailable"))
+}
+
+// serveNothing reads from the connection without responding until the client
+// closes it
+func serveNothing(_ *rhpHost, conn net.Conn) error {
+	_, err := io.Copy(ioutil.Discard, conn)
+	return err
+}
+
+func newRHPHost(t *testing.T, handle func(*rhpHost, net.Conn) error) *rhpHost {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sk, pk := crypto.GenerateKeyPair()
+	h := &rhpHost{sk: sk, pk: pk, l: l, handle: handle}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer func() {
+					_ = conn.Close()
+				}()
+
+				_ = h.handle(h, conn)
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	return h
+}
+
+// handshake performs the host's side of the renter-host key exchange and
+// sends the challenge
+func (h *rhpHost) handshake(conn net.Conn) (aead cipher.AEAD, err error) {
+	var id siatypes.Specifier
+	var req modules.LoopKeyExchangeRequest
+
+	dec := encoding.NewDecoder(conn, encoding.DefaultAllocLimit)
+	if err := dec.Decode(&id); err != nil {
+		return nil, err
+	} else if id != modules.RPCLoopEnter {
+		return nil, errors.New("unexpected rpc " + id.String())
+	} else if err := dec.Decode(&req); err != nil {
+		return nil, err
+	}
+
+	xsk, xpk := crypto.GenerateX25519KeyPair()
+	sig := crypto.SignHash(crypto.HashAll(req.PublicKey, xpk), h.sk)
+	key := crypto.DeriveSharedSecret(xsk, req.PublicKey)
+
+	err = encoding.NewEncoder(conn).Encode(modules.LoopKeyExchangeResponse{
+		PublicKey: xpk,
+		Signature: sig[:],
+		Cipher:    modules.CipherChaCha20Poly1305,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err = chacha20poly1305.New(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return aead, modules.WriteRPCMessage(conn, aead, modules.LoopChallengeRequest{})
+}
+
+// listenMux returns a stand-in for the host's SiaMux port
+func listenMux(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	return port(l.Addr().String())
+}
+
+// closedPort returns a port that is not listening
+func closedPort(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := l.Addr().String()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return port(addr)
+}
+
+func port(addr string) string {
+	_, p, _ := net.SplitHostPort(addr)
+	return p
+}
+
+func hostGet(h *rhpHost, hostPort, muxPort string) api.HostGET {
+	var host api.HostGET
+
+	if h != nil {
+		host.PublicKey = siatypes.Ed25519PublicKey(h.pk)
+	}
+
+	host.ExternalSettings.NetAddress = modules.NetAddress(net.JoinHostPort("127.0.0.1", hostPort))
+	host.ExternalSettings.SiaMuxPort = muxPort
+
+	return host
+}
+
+// connectionAlerts returns the text of the connection alerts
+func connectionAlerts() (alerts []string) {
+	for _, alert := range cache.GetAlerts() {
+		if alert.Type == "connection" {
+			alerts = append(alerts, alert.Text)
+		}
+	}
+
+	return
+}
+
+func TestCheckLocalConnectivity(t *testing.T) {
+	timeout := dialTimeout
+	dialTimeout = 250 * time.Millisecond
+	t.Cleanup(func() {
+		dialTimeout = timeout
+	})
+
+	online := newRHPHost(t, serveSettings(modules.HostExternalSettings{AcceptingContracts: true}))
+	rpcError := newRHPHost(t, serveError)
+	unresponsive := newRHPHost(t, serveNothing)
+	muxPort := listenMux(t)
+
+	tests := []struct {
+		name      string
+		host      api.HostGET
+		reachable bool
+		accepting bool
+		timeout   bool
+		alert     string
+	}{
+		{"open", hostGet(online, port(online.l.Addr().String()), muxPort), true, true, false, ""},
+		{"closed host port", hostGet(online, closedPort(t), muxPort), false, false, false, "Unable to connect to host port"},
+		{"closed siamux port", hostGet(online, port(online.l.Addr().String()), closedPort(t)), true, true, false, "Unable to connect to SiaMux port"},
+		{"wrong host key", hostGet(rpcError, port(online.l.Addr().String()), muxPort), false, false, false, "Host did not respond to settings request"},
+		{"settings rpc error", hostGet(rpcError, port(rpcError.l.Addr().String()), muxPort), false, false, false, "settings unavailable"},
+		{"timeout", hostGet(unresponsive, port(unresponsive.l.Addr().String()), muxPort), false, false, true, "i/o timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache.ClearAlerts(AlertConnectionStatus)
+
+			var check types.ConnectivityCheck
+			start := time.Now()
+			checkLocalConnectivity(tt.host, &check)
+
+			elapsed := time.Since(start)
+			if elapsed > 2*time.Second {
+				t.Fatalf("check took %s, expected the dial timeout to apply", elapsed)
+			} else if tt.timeout && elapsed < dialTimeout {
+				t.Fatalf("check took %s, expected it to wait for the timeout", elapsed)
+			}
+
+			if check.Reachable != tt.reachable {
+				t.Fatalf("expected reachable %t, got %t", tt.reachable, check.Reachable)
+			} else if check.AcceptingContracts != tt.accepting {
+				t.Fatalf("expected accepting contracts %t, got %t", tt.accepting, check.AcceptingContracts)
+			}
+
+			alerts := connectionAlerts()
+			if len(tt.alert) == 0 {
+				if len(alerts) != 0 {
+					t.Fatalf("expected no alerts, got %v", alerts)
+				}
+				return
+			}
+
+			if len(alerts) != 1 || !strings.Contains(alerts[0], tt.alert) {
+				t.Fatalf("expected alert containing %q, got %v", tt.alert, alerts)
+			}
+		})
+	}
+
+	cache.ClearAlerts(AlertConnectionStatus)
+}
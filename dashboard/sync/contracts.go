@@ -2,7 +2,6 @@ package sync
 
 import (
 	"fmt"
-	"log"
 	"net/url"
 	"sync"
 	"time"
@@ -10,61 +9,26 @@ import (
 	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
 	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
 	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/node/api"
 	siatypes "gitlab.com/NebulousLabs/Sia/types"
 )
 
-const (
-	contractStatusSucceeded  = "obligationSucceeded"
-	contractStatusFailed     = "obligationFailed"
-	contractStatusUnresolved = "obligationUnresolved"
-)
+// contractWorkers is the maximum number of contracts resolved concurrently
+const contractWorkers = 8
 
-var (
-	blockMetaCache = make(map[uint64]blockMeta)
-	blockMetaMu    sync.Mutex
-)
+func syncContracts(host api.HostGET) error {
+	cache.ClearAlerts(AlertContractSyncError)
 
-type (
-	blockMeta struct {
-		ID        string
-		Timestamp time.Time
+	stored, err := getStoredContracts()
+	if err != nil {
+		return err
 	}
-)
 
-//merges fields from the local contract db and the blockchain
-type mergedContract struct {
-	ID                     string            `json:"id"`
-	BlockID                string            `json:"blockID"`
-	TransactionID          string            `json:"transactionID"`
-	MerkleRoot             string            `json:"merkleRoot"`
-	UnlockHash             string            `json:"unlockHash"`
-	Status                 string            `json:"status"`
-	RevisionNumber         uint64            `json:"revisionNumber"`
-	NegotiationHeight      uint64            `json:"negotiationHeight"`
-	ExpirationHeight       uint64            `json:"expirationHeight"`
-	ProofDeadline          uint64            `json:"proofDeadline"`
-	ProofHeight            uint64            `json:"proofHeight"`
-	DataSize               uint64            `json:"fileSize"`
-	ProofConfirmed         bool              `json:"proofConfirmed"`
-	NegotiationTimestamp   time.Time         `json:"negotiationTimestamp"`
-	ExpirationTimestamp    time.Time         `json:"expirationTimestamp"`
-	ProofDeadlineTimestamp time.Time         `json:"proofDeadlineTimestamp"`
-	ProofTimestamp         time.Time         `json:"proofTimestamp"`
-	Payout                 siatypes.Currency `json:"payout"`
-	LockedCollateral       siatypes.Currency `json:"lockedCollateral"`
-	PotentialRevenue       siatypes.Currency `json:"potentialRevenue"`
-	EarnedRevenue          types.BigNumber   `json:"earnedRevenue"`
-	LostRevenue            siatypes.Currency `json:"lostRevenue"`
-	BurntCollateral        siatypes.Currency `json:"burntCollateral"`
-}
-
-func syncContracts() error {
-	cache.ClearAlerts(AlertSyncError)
-	contracts, err := getContracts()
+	contracts, err := getContracts(stored)
 
 	if err != nil {
-		cache.AddAlert(AlertSyncError, types.HostAlert{
+		cache.AddAlert(AlertContractSyncError, types.HostAlert{
 			Severity: "severe",
 			Text:     "Unable to sync host contracts. Check your Sia connection",
 			Type:     "sync",
@@ -72,12 +36,17 @@ func syncContracts() error {
 		return err
 	}
 
+	// contracts that disappeared are removed from the snapshots even if the
+	// host has no contracts left
+	syncHostSnapshots(stored, contracts)
+
 	if len(contracts) == 0 {
 		return nil
 	}
 
-	syncHostMeta(contracts)
-	syncHostSnapshots(contracts)
+	syncHostMeta(host, contracts)
+	syncCapacityPlan()
+	syncProofRisk(contracts)
 
 	return nil
 }
@@ -100,18 +69,33 @@ func getSyncedHeight() (height uint64, err error) {
 }
 
 func txnConfirmed(id siatypes.TransactionID) (bool, error) {
+	confirmed, err := persist.TransactionConfirmed(id.String())
+	if err != nil {
+		return false, fmt.Errorf("error getting cached transaction: %w", err)
+	} else if confirmed {
+		return true, nil
+	}
+
 	var resp api.TpoolConfirmedGET
-	err := apiClient.Get("/tpool/confirmed/"+url.PathEscape(id.String()), &resp)
-	return resp.Confirmed, err
-}
+	if err := apiClient.Get("/tpool/confirmed/"+url.PathEscape(id.String()), &resp); err != nil {
+		return false, err
+	} else if !resp.Confirmed {
+		return false, nil
+	}
 
-func getBlockMeta(height uint64) (time.Time, error) {
-	blockMetaMu.Lock()
+	if err := persist.SaveConfirmedTransaction(id.String()); err != nil {
+		return false, fmt.Errorf("error caching transaction: %w", err)
+	}
 
-	defer blockMetaMu.Unlock()
+	return true, nil
+}
 
-	if block, exists := blockMetaCache[height]; exists {
-		return block.Timestamp, nil
+func getBlockMeta(height uint64) (time.Time, error) {
+	timestamp, exists, err := persist.GetBlockTimestamp(height)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting cached block: %w", err)
+	} else if exists {
+		return timestamp, nil
 	}
 
 	block, err := apiClient.ConsensusBlocksHeightGet(siatypes.BlockHeight(height))
@@ -119,181 +103,229 @@ func getBlockMeta(height uint64) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("error getting block: %w", err)
 	}
 
-	blockMetaCache[height] = blockMeta{
-		ID:        block.ID.String(),
-		Timestamp: time.Unix(int64(block.Timestamp), 0),
+	timestamp = time.Unix(int64(block.Timestamp), 0)
+
+	if err := persist.SaveBlockTimestamp(height, timestamp); err != nil {
+		return time.Time{}, fmt.Errorf("error caching block: %w", err)
 	}
 
-	return blockMetaCache[height].Timestamp, nil
+	return timestamp, nil
 }
 
-func getContracts() (contracts []mergedContract, err error) {
-	siaContracts, err := apiClient.HostContractInfoGet()
+// contractResolved returns true if the contract's status and timestamps can no
+// longer change. Contracts synced before their proof deadline have estimated
+// timestamps even if they have succeeded, they are resolved again once the
+// deadline's block exists.
+func contractResolved(contract types.HostContract, currentHeight uint64) bool {
+	if contract.ProofDeadline >= currentHeight || contract.SyncedHeight <= contract.ProofDeadline {
+		return false
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("get sia contracts: %w", err)
+	return contract.Status == types.ContractStatusSucceeded || contract.Status == types.ContractStatusFailed
+}
+
+// estimateBlockTime returns the estimated timestamp of a future block from
+// the number of hours until it is mined
+func estimateBlockTime(height, currentHeight uint64, now time.Time) time.Time {
+	hoursRemaining := time.Duration((height-currentHeight)/uint64(siatypes.BlocksPerHour)) * time.Hour
+	return now.Truncate(time.Hour).Add(hoursRemaining)
+}
+
+// contractRevenue returns the contract's revenue split by income type
+func contractRevenue(siaContract modules.StorageObligation) types.RevenueBreakdown {
+	return types.RevenueBreakdown{
+		ContractRevenue: siaContract.ContractCost,
+		StorageRevenue:  siaContract.PotentialStorageRevenue,
+		UploadRevenue:   siaContract.PotentialUploadRevenue,
+		DownloadRevenue: siaContract.PotentialDownloadRevenue,
+		AccountFunding:  siaContract.PotentialAccountFunding,
 	}
+}
 
-	currentHeight, err := getSyncedHeight()
+// resolveContract merges the host's storage obligation with the blockchain.
+// confirmed is false if the contract's transaction has not been confirmed yet.
+func resolveContract(siaContract modules.StorageObligation, currentHeight uint64) (contract types.HostContract, confirmed bool, err error) {
+	confirmed, err = txnConfirmed(siaContract.TransactionID)
 	if err != nil {
-		return nil, fmt.Errorf("get current height: %w", err)
+		return contract, false, fmt.Errorf("unable to check confirmed txn %s for contract %s: %w", siaContract.TransactionID, siaContract.ObligationId, err)
+	}
+	if !confirmed {
+		return contract, false, nil
 	}
 
-	if len(siaContracts.Contracts) == 0 {
-		return []mergedContract{}, nil
+	contract = types.HostContract{
+		ID:                siaContract.ObligationId.String(),
+		TransactionID:     siaContract.TransactionID.String(),
+		RevisionNumber:    siaContract.RevisionNumber,
+		NegotiationHeight: uint64(siaContract.NegotiationHeight),
+		ExpirationHeight:  uint64(siaContract.ExpirationHeight),
+		ProofDeadline:     uint64(siaContract.ProofDeadLine),
+		SyncedHeight:      currentHeight,
+		ProofConfirmed:    siaContract.ProofConfirmed,
+		DataSize:          siaContract.DataSize,
+		LockedCollateral:  siaContract.LockedCollateral,
+		RiskedCollateral:  siaContract.RiskedCollateral,
+		PotentialRevenue:  siaContract.ValidProofOutputs[1].Value.Sub(siaContract.LockedCollateral),
+		Revenue:           contractRevenue(siaContract),
 	}
 
-	for _, siaContract := range siaContracts.Contracts {
-		confirmed, err := txnConfirmed(siaContract.TransactionID)
-		if err != nil {
-			return nil, fmt.Errorf("unable to check confirmed txn %s for contract %s: %w", siaContract.TransactionID, siaContract.ObligationId, err)
-		}
-		if !confirmed {
-			continue
-		}
+	proofRequired := siaContract.ValidProofOutputs[1].Value.Cmp(siaContract.MissedProofOutputs[1].Value) == 1
+
+	if contract.ProofConfirmed || (!proofRequired && contract.ExpirationHeight < currentHeight) {
+		contract.Status = types.ContractStatusSucceeded
 
-		contract := mergedContract{
-			ID:                siaContract.ObligationId.String(),
-			TransactionID:     siaContract.TransactionID.String(),
-			RevisionNumber:    siaContract.RevisionNumber,
-			NegotiationHeight: uint64(siaContract.NegotiationHeight),
-			ExpirationHeight:  uint64(siaContract.ExpirationHeight),
-			ProofDeadline:     uint64(siaContract.ProofDeadLine),
-			ProofConfirmed:    siaContract.ProofConfirmed,
-			DataSize:          siaContract.DataSize,
-			LockedCollateral:  siaContract.LockedCollateral,
-			PotentialRevenue:  siaContract.ValidProofOutputs[1].Value.Sub(siaContract.LockedCollateral),
+		if contract.ProofConfirmed {
+			contract.Payout = siaContract.ValidProofOutputs[1].Value
+		} else {
+			contract.Payout = siaContract.MissedProofOutputs[1].Value
 		}
 
-		proofRequired := siaContract.ValidProofOutputs[1].Value.Cmp(siaContract.MissedProofOutputs[1].Value) == 1
+		contract.EarnedRevenue = contract.EarnedRevenue.AddCurrency(contract.Payout).SubCurrency(contract.LockedCollateral)
+	} else if !contract.ProofConfirmed && contract.ProofDeadline < currentHeight {
+		contract.Status = types.ContractStatusFailed
+		contract.Payout = siaContract.MissedProofOutputs[1].Value
+		contract.LostRevenue = siaContract.ValidProofOutputs[1].Value.Sub(contract.LockedCollateral)
+		contract.EarnedRevenue = contract.EarnedRevenue.AddCurrency(siaContract.MissedProofOutputs[1].Value).SubCurrency(contract.LockedCollateral)
 
-		if contract.ProofConfirmed || (!proofRequired && contract.ExpirationHeight < currentHeight) {
-			contract.Status = contractStatusSucceeded
+		if siaContract.MissedProofOutputs[1].Value.Cmp(contract.LockedCollateral) == -1 {
+			contract.BurntCollateral = contract.LockedCollateral.Sub(siaContract.MissedProofOutputs[1].Value)
+		}
+	} else {
+		contract.Status = types.ContractStatusUnresolved
+		contract.Payout = siaContract.ValidProofOutputs[1].Value
+		contract.PotentialRevenue = contract.Payout.Sub(contract.LockedCollateral)
+	}
 
-			if contract.ProofConfirmed {
-				contract.Payout = siaContract.ValidProofOutputs[1].Value
-			} else {
-				contract.Payout = siaContract.MissedProofOutputs[1].Value
-			}
+	negotiationTimestamp, err := getBlockMeta(contract.NegotiationHeight)
+	if err != nil {
+		return contract, false, fmt.Errorf("get negotiation height: %w", err)
+	}
 
-			contract.EarnedRevenue = contract.EarnedRevenue.AddCurrency(contract.Payout).SubCurrency(contract.LockedCollateral)
-		} else if !contract.ProofConfirmed && contract.ProofDeadline < currentHeight {
-			contract.Status = contractStatusFailed
-			contract.Payout = siaContract.MissedProofOutputs[1].Value
-			contract.LostRevenue = siaContract.ValidProofOutputs[1].Value.Sub(contract.LockedCollateral)
-			contract.EarnedRevenue = contract.EarnedRevenue.AddCurrency(siaContract.MissedProofOutputs[1].Value).SubCurrency(contract.LockedCollateral)
+	contract.NegotiationTimestamp = negotiationTimestamp
 
-			if siaContract.MissedProofOutputs[1].Value.Cmp(contract.LockedCollateral) == -1 {
-				contract.BurntCollateral = contract.LockedCollateral.Sub(siaContract.MissedProofOutputs[1].Value)
-			}
-		} else {
-			contract.Status = contractStatusUnresolved
-			contract.Payout = siaContract.ValidProofOutputs[1].Value
-			contract.PotentialRevenue = contract.Payout.Sub(contract.LockedCollateral)
+	if currentHeight < contract.ExpirationHeight {
+		contract.ExpirationTimestamp = estimateBlockTime(contract.ExpirationHeight, currentHeight, time.Now())
+	} else {
+		expirationTimestamp, err := getBlockMeta(contract.ExpirationHeight)
+		if err != nil {
+			return contract, false, fmt.Errorf("get expiration height: %w", err)
 		}
 
-		negotiationTimestamp, err := getBlockMeta(contract.NegotiationHeight)
+		contract.ExpirationTimestamp = expirationTimestamp
+	}
+
+	if currentHeight < contract.ProofDeadline {
+		contract.ProofDeadlineTimestamp = estimateBlockTime(contract.ProofDeadline, currentHeight, time.Now())
+	} else {
+		proofDeadlineTimestamp, err := getBlockMeta(contract.ProofDeadline)
 		if err != nil {
-			return nil, fmt.Errorf("get negotiation height: %w", err)
+			return contract, false, fmt.Errorf("get proof deadline height: %w", err)
 		}
 
-		contract.NegotiationTimestamp = negotiationTimestamp
-
-		if currentHeight < contract.ExpirationHeight {
-			hoursRemaining := time.Duration((contract.ExpirationHeight-currentHeight)/uint64(siatypes.BlocksPerHour)) * time.Hour
-			contract.ExpirationTimestamp = time.Now().Truncate(time.Hour).Add(hoursRemaining)
-		} else {
-			expirationTimestamp, err := getBlockMeta(contract.ExpirationHeight)
-			if err != nil {
-				return nil, fmt.Errorf("get expiration height: %w", err)
-			}
+		contract.ProofDeadlineTimestamp = proofDeadlineTimestamp
+	}
 
-			contract.ExpirationTimestamp = expirationTimestamp
-		}
+	return contract, true, nil
+}
 
-		if currentHeight < contract.ProofDeadline {
-			hoursRemaining := time.Duration((contract.ProofDeadline-currentHeight)/uint64(siatypes.BlocksPerHour)) * time.Hour
-			contract.ProofDeadlineTimestamp = time.Now().Truncate(time.Hour).Add(hoursRemaining)
-		} else {
-			proofDeadlineTimestamp, err := getBlockMeta(contract.ProofDeadline)
-			if err != nil {
-				return nil, fmt.Errorf("get negotiation height: %w", err)
+// resolveContracts resolves the storage obligations using a bounded pool of
+// workers. Unconfirmed contracts are not returned.
+func resolveContracts(obligations []modules.StorageObligation, currentHeight uint64) (contracts []types.HostContract, err error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	work := make(chan modules.StorageObligation)
+
+	for i := 0; i < contractWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for obligation := range work {
+				contract, confirmed, resolveErr := resolveContract(obligation, currentHeight)
+
+				mu.Lock()
+				if resolveErr != nil && err == nil {
+					err = resolveErr
+				} else if confirmed {
+					contracts = append(contracts, contract)
+				}
+				mu.Unlock()
 			}
+		}()
+	}
 
-			contract.ProofDeadlineTimestamp = proofDeadlineTimestamp
+	for _, obligation := range obligations {
+		mu.Lock()
+		failed := err != nil
+		mu.Unlock()
+
+		if failed {
+			break
 		}
 
-		contracts = append(contracts, contract)
+		work <- obligation
 	}
 
+	close(work)
+	wg.Wait()
+
 	return
 }
 
-func syncHostSnapshots(contracts []mergedContract) {
-	snapshotMap := make(map[uint64]types.HostSnapshot)
-
-	for _, contract := range contracts {
-		endTimestamp := snapshotTime(contract.ProofDeadlineTimestamp)
+// getStoredContracts returns the contracts from the last sync mapped by id
+func getStoredContracts() (map[string]types.HostContract, error) {
+	stored, err := persist.GetContracts()
+	if err != nil {
+		return nil, fmt.Errorf("get stored contracts: %w", err)
+	}
 
-		for current := snapshotTime(contract.NegotiationTimestamp); current.Before(endTimestamp); current = current.Add(time.Hour) {
-			activeID := snapshotID(current)
-			snapshot := snapshotMap[activeID]
-			snapshot.ActiveContracts++
-			snapshot.Timestamp = time.Unix(int64(activeID), 0)
-			snapshotMap[activeID] = snapshot
-		}
+	contracts := make(map[string]types.HostContract, len(stored))
+	for _, contract := range stored {
+		contracts[contract.ID] = contract
+	}
 
-		switch contract.Status {
-		case contractStatusSucceeded:
-			var successfulID uint64
+	return contracts, nil
+}
 
-			if contract.ProofConfirmed {
-				successfulID = snapshotID(contract.ExpirationTimestamp)
-			} else {
-				successfulID = snapshotID(contract.ProofDeadlineTimestamp)
-			}
+// getContracts returns the host's confirmed contracts. Contracts that have
+// already been resolved are reused from the stored contracts, only contracts
+// whose status could still change are re-evaluated.
+func getContracts(stored map[string]types.HostContract) (contracts []types.HostContract, err error) {
+	siaContracts, err := apiClient.HostContractInfoGet()
 
-			snapshot := snapshotMap[successfulID]
-			snapshot.Timestamp = time.Unix(int64(successfulID), 0)
-			snapshot.SuccessfulContracts++
-
-			snapshot.EarnedRevenue = snapshot.EarnedRevenue.Add(contract.EarnedRevenue)
-			snapshotMap[successfulID] = snapshot
-		case contractStatusFailed:
-			failedID := snapshotID(contract.ProofDeadlineTimestamp)
-			snapshot := snapshotMap[failedID]
-
-			snapshot.FailedContracts++
-			snapshot.EarnedRevenue = snapshot.EarnedRevenue.Add(contract.EarnedRevenue)
-			snapshot.BurntCollateral = snapshot.BurntCollateral.Add(contract.BurntCollateral)
-			snapshot.Timestamp = time.Unix(int64(failedID), 0)
-
-			snapshotMap[failedID] = snapshot
-		case contractStatusUnresolved:
-			expirationID := snapshotID(contract.ExpirationTimestamp)
-			snapshot := snapshotMap[expirationID]
-			snapshot.PotentialRevenue = snapshot.PotentialRevenue.Add(contract.PotentialRevenue)
-			snapshot.ExpiredContracts++
-			snapshot.ActiveContracts--
-			snapshot.Timestamp = time.Unix(int64(expirationID), 0)
-			snapshotMap[expirationID] = snapshot
-		}
+	if err != nil {
+		return nil, fmt.Errorf("get sia contracts: %w", err)
+	}
 
-		formationID := snapshotID(contract.NegotiationTimestamp)
-		formationSnapshot := snapshotMap[formationID]
+	currentHeight, err := getSyncedHeight()
+	if err != nil {
+		return nil, fmt.Errorf("get current height: %w", err)
+	}
 
-		formationSnapshot.NewContracts++
-		formationSnapshot.Timestamp = time.Unix(int64(formationID), 0)
-		snapshotMap[formationID] = formationSnapshot
+	if len(siaContracts.Contracts) == 0 {
+		return []types.HostContract{}, nil
 	}
 
-	snapshots := []types.HostSnapshot{}
+	var pending []modules.StorageObligation
+	for _, siaContract := range siaContracts.Contracts {
+		if contract, exists := stored[siaContract.ObligationId.String()]; exists && contractResolved(contract, currentHeight) {
+			// the revenue breakdown does not require any lookups, refresh it
+			// in case the contract was stored before it was tracked
+			contract.Revenue = contractRevenue(siaContract)
+			contracts = append(contracts, contract)
+			continue
+		}
 
-	for _, snapshot := range snapshotMap {
-		snapshots = append(snapshots, snapshot)
+		pending = append(pending, siaContract)
 	}
 
-	if err := persist.SaveHostSnapshots(snapshots...); err != nil {
-		log.Printf("sync error: save snapshotMap: %s", err)
+	updated, err := resolveContracts(pending, currentHeight)
+	if err != nil {
+		return nil, err
 	}
+
+	contracts = append(contracts, updated...)
+
+	return
 }
@@ -0,0 +1,39 @@
+package sync
+
+import (
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+func convertFinancialMetrics(metrics modules.HostFinancialMetrics) types.HostFinancialMetrics {
+	return types.HostFinancialMetrics{
+		AccountFunding:                    metrics.AccountFunding,
+		PotentialAccountFunding:           metrics.PotentialAccountFunding,
+		ContractCount:                     metrics.ContractCount,
+		ContractCompensation:              metrics.ContractCompensation,
+		PotentialContractCompensation:     metrics.PotentialContractCompensation,
+		LockedStorageCollateral:           metrics.LockedStorageCollateral,
+		LostRevenue:                       metrics.LostRevenue,
+		LostStorageCollateral:             metrics.LostStorageCollateral,
+		PotentialStorageRevenue:           metrics.PotentialStorageRevenue,
+		RiskedStorageCollateral:           metrics.RiskedStorageCollateral,
+		StorageRevenue:                    metrics.StorageRevenue,
+		TransactionFeeExpenses:            metrics.TransactionFeeExpenses,
+		DownloadBandwidthRevenue:          metrics.DownloadBandwidthRevenue,
+		PotentialDownloadBandwidthRevenue: metrics.PotentialDownloadBandwidthRevenue,
+		PotentialUploadBandwidthRevenue:   metrics.PotentialUploadBandwidthRevenue,
+		UploadBandwidthRevenue:            metrics.UploadBandwidthRevenue,
+	}
+}
+
+func convertNetworkMetrics(metrics modules.HostNetworkMetrics) types.HostNetworkMetrics {
+	return types.HostNetworkMetrics{
+		DownloadCalls:     metrics.DownloadCalls,
+		ErrorCalls:        metrics.ErrorCalls,
+		FormContractCalls: metrics.FormContractCalls,
+		RenewCalls:        metrics.RenewCalls,
+		ReviseCalls:       metrics.ReviseCalls,
+		SettingsCalls:     metrics.SettingsCalls,
+		UnrecognizedCalls: metrics.UnrecognizedCalls,
+	}
+}
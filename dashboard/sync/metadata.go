@@ -2,30 +2,32 @@ package sync
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/siacentral/apisdkgo"
 	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
 	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
 	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"gitlab.com/NebulousLabs/Sia/node/api"
 )
 
 var siacentralapi = apisdkgo.NewSiaClient()
 
-func calcHostContracts(contracts []mergedContract, meta *types.HostMeta) {
+func calcHostContracts(contracts []types.HostContract, meta *types.HostMeta) {
 	for _, contract := range contracts {
 		meta.Payout = meta.Payout.Add(contract.Payout)
 		meta.EarnedRevenue = meta.EarnedRevenue.Add(contract.EarnedRevenue)
 		meta.BurntCollateral = meta.BurntCollateral.Add(contract.BurntCollateral)
 
 		switch contract.Status {
-		case contractStatusSucceeded:
+		case types.ContractStatusSucceeded:
 			meta.SuccessfulContracts++
-		case contractStatusFailed:
+			meta.EarnedBreakdown = meta.EarnedBreakdown.Add(contract.Revenue)
+		case types.ContractStatusFailed:
 			meta.FailedContracts++
-		case contractStatusUnresolved:
+		case types.ContractStatusUnresolved:
 			meta.PotentialRevenue = meta.PotentialRevenue.Add(contract.PotentialRevenue)
+			meta.PotentialBreakdown = meta.PotentialBreakdown.Add(contract.Revenue)
 			meta.ActiveContracts++
 		}
 	}
@@ -43,17 +45,13 @@ func getFirstSeen(pubkey string, meta *types.HostMeta) error {
 	return nil
 }
 
-func syncHostMeta(contracts []mergedContract) {
+func syncHostMeta(host api.HostGET, contracts []types.HostContract) {
 	var meta types.HostMeta
 
-	cache.ClearAlerts(AlertSyncError)
-
 	calcHostContracts(contracts, &meta)
 
-	host, err := apiClient.HostGet()
-
-	if err != nil {
-		cache.AddAlert(AlertSyncError, types.HostAlert{
+	if err := getFirstSeen(host.PublicKey.String(), &meta); err != nil {
+		cache.AddAlert(AlertContractSyncError, types.HostAlert{
 			Severity: "severe",
 			Text:     "Unable to sync host. Check your Sia connection.",
 			Type:     "sync",
@@ -61,13 +59,17 @@ func syncHostMeta(contracts []mergedContract) {
 		return
 	}
 
-	if err := getFirstSeen(host.PublicKey.String(), &meta); err != nil {
-		cache.AddAlert(AlertSyncError, types.HostAlert{
-			Severity: "severe",
-			Text:     "Unable to sync host. Check your Sia connection.",
-			Type:     "sync",
+	storage, err := apiClient.HostStorageGet()
+	if err != nil {
+		logger.Warn("unable to retrieve storage folders", "error", err)
+	}
+
+	for _, folder := range storage.Folders {
+		meta.Folders = append(meta.Folders, types.StorageFolder{
+			Path:        folder.Path,
+			Capacity:    folder.Capacity,
+			UsedStorage: folder.Capacity - folder.CapacityRemaining,
 		})
-		return
 	}
 
 	up, down := getBandwidthUsage()
@@ -76,6 +78,11 @@ func syncHostMeta(contracts []mergedContract) {
 	meta.DownloadBandwidth = down
 	meta.UsedStorage = host.ExternalSettings.TotalStorage - host.ExternalSettings.RemainingStorage
 	meta.TotalStorage = host.ExternalSettings.TotalStorage
+	meta.LockedCollateral = host.FinancialMetrics.LockedStorageCollateral
+	meta.RiskedCollateral = host.FinancialMetrics.RiskedStorageCollateral
+	meta.CollateralBudget = host.InternalSettings.CollateralBudget
+	meta.FinancialMetrics = convertFinancialMetrics(host.FinancialMetrics)
+	meta.NetworkMetrics = convertNetworkMetrics(host.NetworkMetrics)
 	meta.Settings.BaseRPCPrice = host.ExternalSettings.BaseRPCPrice
 	meta.Settings.Collateral = host.ExternalSettings.Collateral
 	meta.Settings.MaxCollateral = host.ExternalSettings.MaxCollateral
@@ -87,6 +94,6 @@ func syncHostMeta(contracts []mergedContract) {
 	meta.Timestamp = time.Now().UTC().Truncate(time.Hour)
 
 	if err := persist.SaveHostMeta(meta); err != nil {
-		log.Printf("sync error: save meta: %s", err)
+		logger.Error("unable to save metadata", "error", err)
 	}
 }
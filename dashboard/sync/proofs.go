@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// proofRiskSevereBlocks alerts are severe once fewer blocks than this
+	// remain before the proof deadline
+	proofRiskSevereBlocks = 72
+	// proofRiskCriticalBlocks alerts include the collateral at stake once
+	// fewer blocks than this remain before the proof deadline
+	proofRiskCriticalBlocks = 12
+)
+
+// contractsAtRisk returns the unresolved contracts whose proof window is open
+// without a confirmed storage proof, sorted by blocks remaining. Unresolved
+// contracts past their expiration height always require a proof.
+func contractsAtRisk(contracts []types.HostContract, currentHeight uint64) (risks []types.ContractRisk) {
+	for _, contract := range contracts {
+		if contract.Status != types.ContractStatusUnresolved || contract.ProofConfirmed {
+			continue
+		}
+
+		if currentHeight <= contract.ExpirationHeight || currentHeight > contract.ProofDeadline {
+			continue
+		}
+
+		risk := types.ContractRisk{
+			ContractID:             contract.ID,
+			ExpirationHeight:       contract.ExpirationHeight,
+			ProofDeadline:          contract.ProofDeadline,
+			BlocksRemaining:        contract.ProofDeadline - currentHeight,
+			ProofDeadlineTimestamp: contract.ProofDeadlineTimestamp,
+			LockedCollateral:       contract.LockedCollateral,
+			RiskedCollateral:       contract.RiskedCollateral,
+			PotentialRevenue:       contract.PotentialRevenue,
+			Severity:               "warning",
+		}
+
+		if risk.BlocksRemaining < proofRiskSevereBlocks {
+			risk.Severity = "severe"
+		}
+
+		risks = append(risks, risk)
+	}
+
+	sort.Slice(risks, func(i, j int) bool {
+		return risks[i].BlocksRemaining < risks[j].BlocksRemaining
+	})
+
+	return
+}
+
+// addProofRiskAlert adds a single alert summarizing the contracts. The format
+// is passed the number of contracts and the fewest blocks remaining followed
+// by args. risks must be sorted by blocks remaining.
+func addProofRiskAlert(severity, format string, risks []types.ContractRisk, args ...interface{}) {
+	if len(risks) == 0 {
+		return
+	}
+
+	args = append([]interface{}{len(risks), risks[0].BlocksRemaining}, args...)
+
+	cache.AddAlert(AlertProofDeadline, types.HostAlert{
+		Severity: severity,
+		Text:     fmt.Sprintf(format, args...),
+		Type:     "contracts",
+	})
+}
+
+// syncProofRisk raises escalating alerts for contracts whose storage proof has
+// not been confirmed as their proof deadline approaches
+func syncProofRisk(contracts []types.HostContract) {
+	var warning, severe, critical []types.ContractRisk
+
+	currentHeight, err := getSyncedHeight()
+	if err != nil {
+		logger.Error("unable to get current height", "error", err)
+		return
+	}
+
+	risks := contractsAtRisk(contracts, currentHeight)
+	cache.SetContractsAtRisk(risks)
+	cache.ClearAlerts(AlertProofDeadline)
+
+	for _, risk := range risks {
+		switch {
+		case risk.BlocksRemaining < proofRiskCriticalBlocks:
+			critical = append(critical, risk)
+		case risk.BlocksRemaining < proofRiskSevereBlocks:
+			severe = append(severe, risk)
+		default:
+			warning = append(warning, risk)
+		}
+	}
+
+	addProofRiskAlert("warning", "%d contracts are waiting for a storage proof, %d blocks until the proof deadline.", warning)
+	addProofRiskAlert("severe", "%d contracts have not submitted a storage proof with %d blocks remaining. Check that Sia is synced and the wallet is unlocked.", severe)
+
+	var collateral siatypes.Currency
+	for _, risk := range critical {
+		collateral = collateral.Add(risk.RiskedCollateral)
+	}
+
+	addProofRiskAlert("severe", "%d contracts will fail in %d blocks without a storage proof, %s collateral at risk.", critical, collateral.HumanString())
+}
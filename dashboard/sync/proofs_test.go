@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+)
+
+// proofContract returns an unresolved contract with a proof window of
+// (expiration, deadline]
+func proofContract(id string, expiration, deadline uint64) types.HostContract {
+	return types.HostContract{
+		ID:               id,
+		Status:           types.ContractStatusUnresolved,
+		ExpirationHeight: expiration,
+		ProofDeadline:    deadline,
+	}
+}
+
+func TestContractsAtRisk(t *testing.T) {
+	confirmed := proofContract("confirmed", 1000, 1144)
+	confirmed.ProofConfirmed = true
+
+	succeeded := proofContract("succeeded", 1000, 1144)
+	succeeded.Status = types.ContractStatusSucceeded
+
+	tests := []struct {
+		name      string
+		contracts []types.HostContract
+		height    uint64
+		// expected contract ids, blocks remaining, and severities in order
+		ids        []string
+		remaining  []uint64
+		severities []string
+	}{
+		{"no contracts", nil, 1000, nil, nil, nil},
+		{"before expiration", []types.HostContract{proofContract("a", 1000, 1144)}, 900, nil, nil, nil},
+		{"at expiration", []types.HostContract{proofContract("a", 1000, 1144)}, 1000, nil, nil, nil},
+		{"window open", []types.HostContract{proofContract("a", 1000, 1144)}, 1001, []string{"a"}, []uint64{143}, []string{"warning"}},
+		{"severe", []types.HostContract{proofContract("a", 1000, 1144)}, 1073, []string{"a"}, []uint64{71}, []string{"severe"}},
+		{"at deadline", []types.HostContract{proofContract("a", 1000, 1144)}, 1144, []string{"a"}, []uint64{0}, []string{"severe"}},
+		{"past deadline", []types.HostContract{proofContract("a", 1000, 1144)}, 1145, nil, nil, nil},
+		{"proof confirmed", []types.HostContract{confirmed}, 1050, nil, nil, nil},
+		{"resolved", []types.HostContract{succeeded}, 1050, nil, nil, nil},
+		{
+			name: "sorted by blocks remaining",
+			contracts: []types.HostContract{
+				proofContract("later", 1000, 1200),
+				proofContract("sooner", 1000, 1100),
+				proofContract("active", 1100, 1244),
+			},
+			height:     1050,
+			ids:        []string{"sooner", "later"},
+			remaining:  []uint64{50, 150},
+			severities: []string{"severe", "warning"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risks := contractsAtRisk(tt.contracts, tt.height)
+			if len(risks) != len(tt.ids) {
+				t.Fatalf("expected %d contracts at risk, got %d", len(tt.ids), len(risks))
+			}
+
+			for i, risk := range risks {
+				if risk.ContractID != tt.ids[i] {
+					t.Fatalf("expected contract %q at %d, got %q", tt.ids[i], i, risk.ContractID)
+				} else if risk.BlocksRemaining != tt.remaining[i] {
+					t.Fatalf("expected %d blocks remaining, got %d", tt.remaining[i], risk.BlocksRemaining)
+				} else if risk.Severity != tt.severities[i] {
+					t.Fatalf("expected severity %q, got %q", tt.severities[i], risk.Severity)
+				}
+			}
+		})
+	}
+}
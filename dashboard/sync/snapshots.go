@@ -0,0 +1,579 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	hourSeconds = uint64(time.Hour / time.Second)
+
+	// contractEventRetention how long status changes are kept after they
+	// have been applied to the snapshots
+	contractEventRetention = 90 * 24 * time.Hour
+)
+
+type (
+	// hourContribution is a single contract's contribution to an hourly
+	// snapshot
+	hourContribution struct {
+		active     int64
+		created    int64
+		expired    int64
+		successful int64
+		failed     int64
+
+		earnedRevenue      types.BigNumber
+		potentialRevenue   siatypes.Currency
+		burntCollateral    siatypes.Currency
+		earnedBreakdown    types.RevenueBreakdown
+		potentialBreakdown types.RevenueBreakdown
+	}
+
+	// contractChange is a contract that changed since the last sync. Either
+	// previous or current is nil if the contract was created or removed.
+	contractChange struct {
+		previous *types.HostContract
+		current  *types.HostContract
+	}
+)
+
+// addCount adds n to v or subtracts it if remove is true. ok is false if the
+// result would be negative.
+func addCount(v uint64, n int64, remove bool) (_ uint64, ok bool) {
+	if remove {
+		n = -n
+	}
+
+	if n < 0 && uint64(-n) > v {
+		return 0, false
+	}
+
+	return uint64(int64(v) + n), true
+}
+
+// apply adds the contribution to the snapshot or subtracts it if remove is
+// true. consistent is false if subtracting would make a value negative.
+func (c hourContribution) apply(snapshot types.HostSnapshot, remove bool) (_ types.HostSnapshot, consistent bool) {
+	counts := []struct {
+		v *uint64
+		n int64
+	}{
+		{&snapshot.ActiveContracts, c.active},
+		{&snapshot.NewContracts, c.created},
+		{&snapshot.ExpiredContracts, c.expired},
+		{&snapshot.SuccessfulContracts, c.successful},
+		{&snapshot.FailedContracts, c.failed},
+	}
+
+	for _, count := range counts {
+		var ok bool
+		if *count.v, ok = addCount(*count.v, count.n, remove); !ok {
+			return snapshot, false
+		}
+	}
+
+	if !remove {
+		snapshot.EarnedRevenue = snapshot.EarnedRevenue.Add(c.earnedRevenue)
+		snapshot.PotentialRevenue = snapshot.PotentialRevenue.Add(c.potentialRevenue)
+		snapshot.BurntCollateral = snapshot.BurntCollateral.Add(c.burntCollateral)
+		snapshot.EarnedBreakdown = snapshot.EarnedBreakdown.Add(c.earnedBreakdown)
+		snapshot.PotentialBreakdown = snapshot.PotentialBreakdown.Add(c.potentialBreakdown)
+		return snapshot, true
+	}
+
+	if snapshot.PotentialRevenue.Cmp(c.potentialRevenue) < 0 || snapshot.BurntCollateral.Cmp(c.burntCollateral) < 0 {
+		return snapshot, false
+	}
+
+	snapshot.EarnedRevenue = snapshot.EarnedRevenue.Sub(c.earnedRevenue)
+	snapshot.PotentialRevenue = snapshot.PotentialRevenue.Sub(c.potentialRevenue)
+	snapshot.BurntCollateral = snapshot.BurntCollateral.Sub(c.burntCollateral)
+
+	if snapshot.EarnedBreakdown, consistent = snapshot.EarnedBreakdown.Sub(c.earnedBreakdown); !consistent {
+		return snapshot, false
+	}
+
+	if snapshot.PotentialBreakdown, consistent = snapshot.PotentialBreakdown.Sub(c.potentialBreakdown); !consistent {
+		return snapshot, false
+	}
+
+	return snapshot, true
+}
+
+func snapshotEmpty(snapshot types.HostSnapshot) bool {
+	return snapshot.ActiveContracts == 0 && snapshot.NewContracts == 0 &&
+		snapshot.ExpiredContracts == 0 && snapshot.SuccessfulContracts == 0 &&
+		snapshot.FailedContracts == 0 && snapshot.EarnedRevenue.IsZero() &&
+		snapshot.PotentialRevenue.IsZero() && snapshot.BurntCollateral.IsZero() &&
+		snapshot.EarnedBreakdown.IsZero() && snapshot.PotentialBreakdown.IsZero()
+}
+
+// contractActiveHours returns the range of hours the contract is active
+// [start, end)
+func contractActiveHours(contract types.HostContract) (start, end uint64) {
+	return snapshotID(contract.NegotiationTimestamp), snapshotID(contract.ProofDeadlineTimestamp)
+}
+
+// contractResolutionHour returns the hour the contract's status is counted in
+func contractResolutionHour(contract types.HostContract) uint64 {
+	if contract.Status == types.ContractStatusUnresolved || (contract.Status == types.ContractStatusSucceeded && contract.ProofConfirmed) {
+		return snapshotID(contract.ExpirationTimestamp)
+	}
+
+	return snapshotID(contract.ProofDeadlineTimestamp)
+}
+
+// contractContribution returns the contract's contribution to the snapshot of
+// the specified hour
+func contractContribution(contract types.HostContract, id uint64) (c hourContribution) {
+	if start, end := contractActiveHours(contract); id >= start && id < end {
+		c.active++
+	}
+
+	if id == snapshotID(contract.NegotiationTimestamp) {
+		c.created++
+	}
+
+	if id != contractResolutionHour(contract) {
+		return
+	}
+
+	switch contract.Status {
+	case types.ContractStatusSucceeded:
+		c.successful++
+		c.earnedRevenue = contract.EarnedRevenue
+		c.earnedBreakdown = contract.Revenue
+	case types.ContractStatusFailed:
+		c.failed++
+		c.earnedRevenue = contract.EarnedRevenue
+		c.burntCollateral = contract.BurntCollateral
+	case types.ContractStatusUnresolved:
+		c.potentialRevenue = contract.PotentialRevenue
+		c.potentialBreakdown = contract.Revenue
+		c.expired++
+
+		// the contract is no longer active once it expires. Contracts that
+		// expire in the hour of their proof deadline were not counted.
+		if c.active > 0 {
+			c.active--
+		}
+	}
+
+	return
+}
+
+// contractPointHours returns the hours a contract contributes to outside of
+// its active range
+func contractPointHours(contract types.HostContract) []uint64 {
+	return []uint64{snapshotID(contract.NegotiationTimestamp), contractResolutionHour(contract)}
+}
+
+// addHourRange adds every hour in [start, end) to the set
+func addHourRange(hours map[uint64]bool, start, end uint64) {
+	for id := start; id < end; id += hourSeconds {
+		hours[id] = true
+	}
+}
+
+// affectedHours returns the sorted hours whose snapshots are changed by the
+// contract change. Hours where both the previous and current contract are
+// only counted as active are skipped since the contributions cancel out.
+func affectedHours(change contractChange) []uint64 {
+	var prevStart, prevEnd, curStart, curEnd uint64
+
+	hours := make(map[uint64]bool)
+
+	if change.previous != nil {
+		prevStart, prevEnd = contractActiveHours(*change.previous)
+
+		for _, id := range contractPointHours(*change.previous) {
+			hours[id] = true
+		}
+	}
+
+	if change.current != nil {
+		curStart, curEnd = contractActiveHours(*change.current)
+
+		for _, id := range contractPointHours(*change.current) {
+			hours[id] = true
+		}
+	}
+
+	// symmetric difference of the two active ranges
+	addHourRange(hours, prevStart, minHour(prevEnd, curStart))
+	addHourRange(hours, maxHour(prevStart, curEnd), prevEnd)
+	addHourRange(hours, curStart, minHour(curEnd, prevStart))
+	addHourRange(hours, maxHour(curStart, prevEnd), curEnd)
+
+	sorted := make([]uint64, 0, len(hours))
+	for id := range hours {
+		sorted = append(sorted, id)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return sorted
+}
+
+func minHour(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxHour(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// blockTimeChanged returns true if the timestamp of the block at the height
+// moved to a different hour. Timestamps of future blocks are estimated from
+// the clock and drift between syncs, they are only compared once the block
+// has been mined.
+func blockTimeChanged(height uint64, previous, current types.HostContract, previousTime, currentTime time.Time) bool {
+	switch {
+	case current.SyncedHeight < height:
+		return false
+	case previous.SyncedHeight != 0 && previous.SyncedHeight < height:
+		// the previous timestamp was an estimate
+		return true
+	}
+
+	return snapshotID(previousTime) != snapshotID(currentTime)
+}
+
+// contractChanged returns true if the contract has changed in a way that
+// affects its stored state or its snapshots
+func contractChanged(previous, current types.HostContract) bool {
+	return previous.Status != current.Status ||
+		previous.ProofConfirmed != current.ProofConfirmed ||
+		previous.RevisionNumber != current.RevisionNumber ||
+		previous.DataSize != current.DataSize ||
+		previous.NegotiationHeight != current.NegotiationHeight ||
+		previous.ExpirationHeight != current.ExpirationHeight ||
+		previous.ProofDeadline != current.ProofDeadline ||
+		snapshotID(previous.NegotiationTimestamp) != snapshotID(current.NegotiationTimestamp) ||
+		blockTimeChanged(current.ExpirationHeight, previous, current, previous.ExpirationTimestamp, current.ExpirationTimestamp) ||
+		blockTimeChanged(current.ProofDeadline, previous, current, previous.ProofDeadlineTimestamp, current.ProofDeadlineTimestamp) ||
+		!previous.Payout.Equals(current.Payout) ||
+		!previous.LockedCollateral.Equals(current.LockedCollateral) ||
+		!previous.RiskedCollateral.Equals(current.RiskedCollateral) ||
+		!previous.PotentialRevenue.Equals(current.PotentialRevenue) ||
+		!previous.EarnedRevenue.Sub(current.EarnedRevenue).IsZero() ||
+		!previous.LostRevenue.Equals(current.LostRevenue) ||
+		!previous.BurntCollateral.Equals(current.BurntCollateral) ||
+		!previous.Revenue.Equals(current.Revenue)
+}
+
+// diffContracts compares the contracts from the last sync with the current
+// contracts and returns an event for each changed contract
+func diffContracts(stored map[string]types.HostContract, contracts []types.HostContract) (events []types.ContractEvent) {
+	timestamp := time.Now().UTC()
+	seen := make(map[string]bool, len(contracts))
+
+	for i := range contracts {
+		current := &contracts[i]
+		seen[current.ID] = true
+
+		previous, exists := stored[current.ID]
+		if !exists {
+			events = append(events, types.ContractEvent{
+				ContractID: current.ID,
+				Type:       types.ContractEventCreated,
+				Status:     current.Status,
+				Timestamp:  timestamp,
+				Current:    current,
+			})
+			continue
+		}
+
+		if !contractChanged(previous, *current) {
+			continue
+		}
+
+		eventType := types.ContractEventUpdated
+		if previous.Status != current.Status {
+			eventType = types.ContractEventStatus
+		}
+
+		events = append(events, types.ContractEvent{
+			ContractID:     current.ID,
+			Type:           eventType,
+			PreviousStatus: previous.Status,
+			Status:         current.Status,
+			Timestamp:      timestamp,
+			Previous:       &previous,
+			Current:        current,
+		})
+	}
+
+	for id, contract := range stored {
+		if seen[id] {
+			continue
+		}
+
+		previous := contract
+		events = append(events, types.ContractEvent{
+			ContractID:     id,
+			Type:           types.ContractEventRemoved,
+			PreviousStatus: previous.Status,
+			Timestamp:      timestamp,
+			Previous:       &previous,
+		})
+	}
+
+	return
+}
+
+// buildHostSnapshots calculates every hourly snapshot from the contracts
+func buildHostSnapshots(contracts []types.HostContract) map[uint64]types.HostSnapshot {
+	snapshotMap := make(map[uint64]types.HostSnapshot)
+
+	for _, contract := range contracts {
+		hours := make(map[uint64]bool)
+		start, end := contractActiveHours(contract)
+
+		addHourRange(hours, start, end)
+		for _, id := range contractPointHours(contract) {
+			hours[id] = true
+		}
+
+		for id := range hours {
+			snapshot, _ := contractContribution(contract, id).apply(snapshotMap[id], false)
+			snapshot.Timestamp = time.Unix(int64(id), 0)
+			snapshotMap[id] = snapshot
+		}
+	}
+
+	return snapshotMap
+}
+
+// updateHostSnapshots applies the events' changes to the stored snapshots of
+// the affected hours and marks the events as applied. consistent is false if
+// the stored snapshots do not match the previous contracts and must be
+// rebuilt.
+func updateHostSnapshots(events []types.ContractEvent) (consistent bool, err error) {
+	var timestamps []time.Time
+
+	changes := make([]contractChange, 0, len(events))
+	for _, event := range events {
+		changes = append(changes, contractChange{previous: event.Previous, current: event.Current})
+	}
+
+	changeHours := make([][]uint64, len(changes))
+	seen := make(map[uint64]bool)
+
+	for i, change := range changes {
+		changeHours[i] = affectedHours(change)
+
+		for _, id := range changeHours[i] {
+			if !seen[id] {
+				seen[id] = true
+				timestamps = append(timestamps, time.Unix(int64(id), 0))
+			}
+		}
+	}
+
+	existing, err := persist.GetHostSnapshotHours(timestamps...)
+	if err != nil {
+		return false, fmt.Errorf("get snapshots: %w", err)
+	}
+
+	snapshotMap := make(map[uint64]types.HostSnapshot, len(timestamps))
+	for _, snapshot := range existing {
+		snapshotMap[snapshotID(snapshot.Timestamp)] = snapshot
+	}
+
+	for i, change := range changes {
+		for _, id := range changeHours[i] {
+			snapshot := snapshotMap[id]
+			snapshot.Timestamp = time.Unix(int64(id), 0)
+
+			if change.previous != nil {
+				if snapshot, consistent = contractContribution(*change.previous, id).apply(snapshot, true); !consistent {
+					return false, nil
+				}
+			}
+
+			if change.current != nil {
+				snapshot, _ = contractContribution(*change.current, id).apply(snapshot, false)
+			}
+
+			snapshotMap[id] = snapshot
+		}
+	}
+
+	var updated []types.HostSnapshot
+	var removed []time.Time
+
+	for _, snapshot := range snapshotMap {
+		if snapshotEmpty(snapshot) {
+			removed = append(removed, snapshot.Timestamp)
+			continue
+		}
+
+		updated = append(updated, snapshot)
+	}
+
+	if err := persist.ApplyContractEvents(updated, removed, events); err != nil {
+		return false, fmt.Errorf("apply contract events: %w", err)
+	}
+
+	return true, nil
+}
+
+func replaceHostSnapshots(contracts []types.HostContract) error {
+	var snapshots []types.HostSnapshot
+
+	for _, snapshot := range buildHostSnapshots(contracts) {
+		if snapshotEmpty(snapshot) {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return persist.ReplaceHostSnapshots(snapshots...)
+}
+
+// saveContractChanges stores the changed contracts and their events. The
+// events are pending until their changes are applied to the snapshots, a sync
+// that fails before then applies them on the next run.
+func saveContractChanges(events []types.ContractEvent) error {
+	var updated []types.HostContract
+	var removed []string
+
+	for _, event := range events {
+		if event.Current == nil {
+			removed = append(removed, event.ContractID)
+			continue
+		}
+
+		updated = append(updated, *event.Current)
+	}
+
+	return persist.SaveContractChanges(updated, removed, events)
+}
+
+// applyContractEvents applies the pending contract events to the snapshots.
+// The snapshots are rebuilt from the contracts if they are inconsistent.
+func applyContractEvents(contracts []types.HostContract) error {
+	events, err := persist.GetPendingContractEvents()
+	if err != nil {
+		return fmt.Errorf("get pending events: %w", err)
+	} else if len(events) == 0 {
+		return nil
+	}
+
+	consistent, err := updateHostSnapshots(events)
+	if err != nil {
+		return err
+	} else if consistent {
+		return nil
+	}
+
+	logger.Warn("stored snapshots are inconsistent, rebuilding")
+
+	if err := replaceHostSnapshots(contracts); err != nil {
+		return fmt.Errorf("rebuild snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// syncHostSnapshots stores the contracts that changed since the last sync and
+// updates the hourly snapshots affected by the changes. Snapshots are rebuilt
+// from scratch if no contracts have been stored yet or the stored snapshots
+// are inconsistent.
+func syncHostSnapshots(stored map[string]types.HostContract, contracts []types.HostContract) {
+	if err := saveContractChanges(diffContracts(stored, contracts)); err != nil {
+		logger.Error("unable to save contract changes", "error", err)
+		return
+	}
+
+	if len(stored) == 0 {
+		if err := replaceHostSnapshots(contracts); err != nil {
+			logger.Error("unable to rebuild snapshots", "error", err)
+			return
+		}
+	} else if err := applyContractEvents(contracts); err != nil {
+		logger.Error("unable to update snapshots", "error", err)
+		return
+	}
+
+	if pruned, err := persist.PruneContractEvents(time.Now().Add(-contractEventRetention)); err != nil {
+		logger.Error("unable to prune contract events", "error", err)
+	} else if pruned > 0 {
+		logger.Debug("pruned contract events", "count", pruned)
+	}
+}
+
+// RebuildSnapshots checks the stored hourly snapshots against the snapshots
+// calculated from the stored contracts and replaces them. Returns the number
+// of hours that were missing, stale, or different.
+func RebuildSnapshots() (mismatched int, err error) {
+	contracts, err := persist.GetContracts()
+	if err != nil {
+		return 0, fmt.Errorf("get stored contracts: %w", err)
+	}
+
+	existing, err := persist.GetAllHostSnapshots()
+	if err != nil {
+		return 0, fmt.Errorf("get snapshots: %w", err)
+	}
+
+	expected := buildHostSnapshots(contracts)
+	for id, snapshot := range expected {
+		if snapshotEmpty(snapshot) {
+			delete(expected, id)
+			continue
+		}
+
+		snapshot.Timestamp = snapshot.Timestamp.UTC()
+		expected[id] = snapshot
+	}
+
+	for _, snapshot := range existing {
+		id := snapshotID(snapshot.Timestamp)
+		calculated, exists := expected[id]
+
+		if !exists {
+			mismatched++
+			continue
+		}
+
+		delete(expected, id)
+
+		a, err := json.Marshal(snapshot)
+		if err != nil {
+			return 0, fmt.Errorf("json encode: %w", err)
+		}
+
+		b, err := json.Marshal(calculated)
+		if err != nil {
+			return 0, fmt.Errorf("json encode: %w", err)
+		}
+
+		if string(a) != string(b) {
+			mismatched++
+		}
+	}
+
+	mismatched += len(expected)
+
+	if err := replaceHostSnapshots(contracts); err != nil {
+		return 0, fmt.Errorf("replace snapshots: %w", err)
+	}
+
+	return mismatched, nil
+}
@@ -0,0 +1,214 @@
+package sync
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+// syncedContract returns an unresolved contract as it is resolved at the
+// height and time. Timestamps of future blocks are estimated.
+func syncedContract(height uint64, now time.Time) types.HostContract {
+	contract := types.HostContract{
+		ID:                   "contract",
+		Status:               types.ContractStatusUnresolved,
+		NegotiationHeight:    100,
+		ExpirationHeight:     1000,
+		ProofDeadline:        1144,
+		SyncedHeight:         height,
+		NegotiationTimestamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	contract.ExpirationTimestamp = estimateBlockTime(contract.ExpirationHeight, height, now)
+	contract.ProofDeadlineTimestamp = estimateBlockTime(contract.ProofDeadline, height, now)
+
+	return contract
+}
+
+func TestDiffContractsEstimates(t *testing.T) {
+	start := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	mined := syncedContract(1000, start.Add(time.Hour))
+	mined.ExpirationTimestamp = start.Add(5 * time.Minute)
+
+	extended := syncedContract(500, start.Add(time.Hour))
+	extended.ExpirationHeight += 144
+
+	tests := []struct {
+		name     string
+		previous types.HostContract
+		current  types.HostContract
+		changed  bool
+	}{
+		{"same hour", syncedContract(500, start), syncedContract(500, start.Add(10*time.Minute)), false},
+		{"next hour", syncedContract(500, start), syncedContract(506, start.Add(time.Hour)), false},
+		{"next hour without blocks", syncedContract(500, start), syncedContract(500, start.Add(time.Hour)), false},
+		{"partial hour of blocks", syncedContract(500, start.Add(55*time.Minute)), syncedContract(501, start.Add(65*time.Minute)), false},
+		{"expiration mined", syncedContract(995, start), mined, true},
+		{"expiration extended", syncedContract(500, start), extended, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := map[string]types.HostContract{tt.previous.ID: tt.previous}
+
+			events := diffContracts(stored, []types.HostContract{tt.current})
+			if tt.changed && len(events) != 1 {
+				t.Fatalf("expected an event, got %d", len(events))
+			} else if !tt.changed && len(events) != 0 {
+				t.Fatalf("expected no events, got %v", events)
+			}
+		})
+	}
+}
+
+// testContract returns an unresolved contract negotiated at the timestamp
+// that expires after the number of days
+func testContract(id string, negotiated time.Time, days int) types.HostContract {
+	expiration := negotiated.AddDate(0, 0, days)
+	revenue := siatypes.SiacoinPrecision.Mul64(uint64(days))
+
+	return types.HostContract{
+		ID:                     id,
+		Status:                 types.ContractStatusUnresolved,
+		NegotiationTimestamp:   negotiated,
+		ExpirationTimestamp:    expiration,
+		ProofDeadlineTimestamp: expiration.Add(24 * time.Hour),
+		PotentialRevenue:       revenue,
+		Revenue: types.RevenueBreakdown{
+			StorageRevenue: revenue,
+		},
+	}
+}
+
+func succeeded(contract types.HostContract) types.HostContract {
+	contract.Status = types.ContractStatusSucceeded
+	contract.ProofConfirmed = true
+	contract.EarnedRevenue = contract.EarnedRevenue.AddCurrency(contract.PotentialRevenue)
+	return contract
+}
+
+func failed(contract types.HostContract) types.HostContract {
+	contract.Status = types.ContractStatusFailed
+	contract.BurntCollateral = siatypes.SiacoinPrecision
+	contract.LostRevenue = contract.PotentialRevenue
+	contract.EarnedRevenue = contract.EarnedRevenue.SubCurrency(contract.BurntCollateral)
+	return contract
+}
+
+func revised(contract types.HostContract) types.HostContract {
+	contract.RevisionNumber++
+	contract.DataSize += 1 << 22
+	contract.PotentialRevenue = contract.PotentialRevenue.Add(siatypes.SiacoinPrecision)
+	contract.Revenue.UploadRevenue = siatypes.SiacoinPrecision
+	return contract
+}
+
+// encodeSnapshots returns the JSON encoded non-empty snapshots by hour
+func encodeSnapshots(t *testing.T, snapshots []types.HostSnapshot) map[uint64]string {
+	t.Helper()
+
+	encoded := make(map[uint64]string)
+	for _, snapshot := range snapshots {
+		if snapshotEmpty(snapshot) {
+			continue
+		}
+
+		snapshot.Timestamp = snapshot.Timestamp.UTC()
+		buf, err := json.Marshal(snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded[snapshotID(snapshot.Timestamp)] = string(buf)
+	}
+
+	return encoded
+}
+
+func TestIncrementalSnapshots(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 20, 0, 0, time.UTC)
+
+	a := testContract("a", start, 10)
+	b := testContract("b", start.Add(90*time.Minute), 4)
+	c := testContract("c", start.AddDate(0, 0, 2), 6)
+	d := testContract("d", start.AddDate(0, 0, 3).Add(30*time.Minute), 8)
+
+	tests := []struct {
+		name    string
+		initial []types.HostContract
+		updated []types.HostContract
+	}{
+		{"added", []types.HostContract{a, b}, []types.HostContract{a, b, c}},
+		{"removed", []types.HostContract{a, b, c}, []types.HostContract{a, c}},
+		{"succeeded", []types.HostContract{a, b}, []types.HostContract{succeeded(a), b}},
+		{"failed", []types.HostContract{a, b}, []types.HostContract{failed(a), b}},
+		{"revised", []types.HostContract{a, b}, []types.HostContract{revised(a), b}},
+		{"mixed", []types.HostContract{a, b, c}, []types.HostContract{succeeded(a), failed(c), revised(d)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := persist.InitializeDB(t.TempDir(), nil); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = persist.CloseDB()
+			})
+
+			// the first sync stores the contracts and builds the snapshots
+			stored := make(map[string]types.HostContract)
+			if err := saveContractChanges(diffContracts(stored, tt.initial)); err != nil {
+				t.Fatal(err)
+			} else if err := replaceHostSnapshots(tt.initial); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, contract := range tt.initial {
+				stored[contract.ID] = contract
+			}
+
+			events := diffContracts(stored, tt.updated)
+			if err := saveContractChanges(events); err != nil {
+				t.Fatal(err)
+			}
+
+			if consistent, err := updateHostSnapshots(events); err != nil {
+				t.Fatal(err)
+			} else if !consistent {
+				t.Fatal("expected the stored snapshots to be consistent")
+			}
+
+			incremental, err := persist.GetAllHostSnapshots()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var rebuilt []types.HostSnapshot
+			for _, snapshot := range buildHostSnapshots(tt.updated) {
+				rebuilt = append(rebuilt, snapshot)
+			}
+
+			got, expected := encodeSnapshots(t, incremental), encodeSnapshots(t, rebuilt)
+			if len(got) != len(expected) {
+				t.Fatalf("expected %d snapshots, got %d", len(expected), len(got))
+			}
+
+			for id, snapshot := range expected {
+				if got[id] != snapshot {
+					t.Fatalf("snapshot %d: expected %s, got %s", id, snapshot, got[id])
+				}
+			}
+
+			if mismatched, err := RebuildSnapshots(); err != nil {
+				t.Fatal(err)
+			} else if mismatched != 0 {
+				t.Fatalf("expected no mismatched snapshots, got %d", mismatched)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
 	"github.com/siacentral/sia-host-dashboard/dashboard/types"
 	"gitlab.com/NebulousLabs/Sia/node/api"
 	siatypes "gitlab.com/NebulousLabs/Sia/types"
@@ -101,24 +102,45 @@ func syncStorageStatus(status *types.HostStatus) error {
 	return nil
 }
 
-func syncHostConnectivity() error {
-	cache.ClearAlerts(AlertConnectionStatus, AlertSyncError)
+// recordConnectivity stores the result of a connectivity check and updates the
+// host's online status
+func recordConnectivity(check types.ConnectivityCheck) {
+	cache.SetConnectivity(check)
 
-	host, err := apiClient.HostGet()
+	if err := persist.SaveConnectivityCheck(check); err != nil {
+		logger.Error("unable to save connectivity check", "error", err)
+	}
+}
 
-	if err != nil {
-		cache.AddAlert(AlertSyncError, types.HostAlert{
-			Severity: "severe",
-			Text:     "Unable to check host connectivity",
-			Type:     "sync",
-		})
-		return fmt.Errorf("sia api get failed: %w", err)
+// connectivityUnavailable records the connectivity check with Sia
+// unreachable when the host could not be retrieved
+func connectivityUnavailable() {
+	cache.ClearAlerts(AlertConnectionStatus, AlertConnectivitySyncError)
+
+	recordConnectivity(types.ConnectivityCheck{
+		Timestamp: time.Now().UTC(),
+	})
+
+	cache.AddAlert(AlertConnectivitySyncError, types.HostAlert{
+		Severity: "severe",
+		Text:     "Unable to check host connectivity",
+		Type:     "sync",
+	})
+}
+
+func syncHostConnectivity(host api.HostGET) error {
+	cache.ClearAlerts(AlertConnectionStatus, AlertConnectivitySyncError)
+
+	check := types.ConnectivityCheck{
+		Timestamp:    time.Now().UTC(),
+		SiaReachable: true,
 	}
 
 	netaddress := string(host.ExternalSettings.NetAddress)
 
 	if len(netaddress) == 0 {
-		cache.AddAlert(AlertSyncError, types.HostAlert{
+		recordConnectivity(check)
+		cache.AddAlert(AlertConnectivitySyncError, types.HostAlert{
 			Severity: "severe",
 			Text:     "Unable to check host connectivity",
 			Type:     "sync",
@@ -126,25 +148,37 @@ func syncHostConnectivity() error {
 		return fmt.Errorf("unable to netaddress")
 	}
 
-	report, err := siacentralapi.GetHostConnectivity(netaddress)
+	if options.ConnectivityCheck == ConnectivityLocal || options.ConnectivityCheck == ConnectivityBoth {
+		checkLocalConnectivity(host, &check)
+	}
 
-	if err != nil {
-		cache.AddAlert(AlertConnectionStatus, types.HostAlert{
-			Severity: "severe",
-			Text:     fmt.Sprintf("Failed to check connectivity: %s", err.Error()),
-			Type:     "connection",
-		})
+	if options.ConnectivityCheck == ConnectivityLocal {
+		recordConnectivity(check)
+		return nil
+	}
+
+	remote := check
+	if err := checkRemoteConnectivity(netaddress, &remote); err != nil {
+		if options.ConnectivityCheck == ConnectivityBoth {
+			// the local check already determined the host's availability
+			addConnectionAlert("warning", "Failed to check connectivity remotely: %s", err)
+			recordConnectivity(check)
+			return nil
+		}
+
+		// the check itself failed, the host's availability is unknown so
+		// nothing is recorded
+		addConnectionAlert("severe", "Failed to check connectivity: %s", err)
 		return fmt.Errorf("failed to check connection: %w", err)
 	}
 
-	for _, err := range report.Errors {
-		cache.AddAlert(AlertConnectionStatus, types.HostAlert{
-			Severity: err.Severity,
-			Text:     err.Message,
-			Type:     err.Type,
-		})
+	if options.ConnectivityCheck == ConnectivityBoth {
+		remote.Reachable = remote.Reachable && check.Reachable
+		remote.AcceptingContracts = remote.AcceptingContracts && check.AcceptingContracts
 	}
 
+	recordConnectivity(remote)
+
 	return nil
 }
 
@@ -163,6 +197,8 @@ func buildStatus(host api.HostGET, wallet api.WalletGET, start time.Time, up, do
 				StoragePrice:           host.ExternalSettings.StoragePrice,
 				UploadBandwidthPrice:   host.ExternalSettings.UploadBandwidthPrice,
 			},
+			FinancialMetrics: convertFinancialMetrics(host.FinancialMetrics),
+			NetworkMetrics:   convertNetworkMetrics(host.NetworkMetrics),
 		},
 		AcceptingContracts: host.InternalSettings.AcceptingContracts,
 		Version:            host.ExternalSettings.Version,
@@ -171,19 +207,18 @@ func buildStatus(host api.HostGET, wallet api.WalletGET, start time.Time, up, do
 	}
 }
 
-func syncHostStatus() error {
-	host, err := apiClient.HostGet()
-
+// statusUnavailable alerts the user when the host could not be retrieved
+func statusUnavailable() {
 	cache.ClearAlerts(AlertSyncError)
+	cache.AddAlert(AlertSyncError, types.HostAlert{
+		Severity: "severe",
+		Text:     "Unable to sync host. Check your Sia connection.",
+		Type:     "sync",
+	})
+}
 
-	if err != nil {
-		cache.AddAlert(AlertSyncError, types.HostAlert{
-			Severity: "severe",
-			Text:     "Unable to sync host. Check your Sia connection.",
-			Type:     "sync",
-		})
-		return fmt.Errorf("get host: %w", err)
-	}
+func syncHostStatus(host api.HostGET) error {
+	cache.ClearAlerts(AlertSyncError)
 
 	wallet, err := apiClient.WalletGet()
 
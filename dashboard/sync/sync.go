@@ -2,25 +2,39 @@ package sync
 
 import (
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
-	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/logging"
+	"gitlab.com/NebulousLabs/Sia/node/api"
 	siaapi "gitlab.com/NebulousLabs/Sia/node/api/client"
 )
 
+const (
+	statusInterval       = 10 * time.Second
+	connectivityInterval = 10 * time.Minute
+	contractsInterval    = 10 * time.Minute
+	retryInterval        = 30 * time.Second
+)
+
 var (
-	apiClient   *siaapi.UnsafeClient
-	counters    *bandwidthCounters
-	bandwidthMu sync.Mutex
+	options   Options
+	logger    *logging.Logger
+	apiClient *siaapi.UnsafeClient
 )
 
-type bandwidthCounters struct {
-	lastUpload    uint64
-	lastDownload  uint64
-	totalUpload   uint64
-	totalDownload uint64
+// Options options when syncing data from Sia
+type Options struct {
+	// SiaAddress the address of the Sia API
+	SiaAddress string
+	// FullAlertDays alerts if the host or a storage folder is projected to
+	// be full within the number of days. Zero disables the alert.
+	FullAlertDays int
+	// ConnectivityCheck how the host's connectivity is checked: local,
+	// remote, or both
+	ConnectivityCheck string
+	// Log the logger for sync errors
+	Log *logging.Logger
 }
 
 func waitInterval(d time.Duration) {
@@ -30,128 +44,148 @@ func waitInterval(d time.Duration) {
 	time.Sleep(sleepTime)
 }
 
-func refreshContracts() {
-	for {
-		if err := syncContracts(); err != nil {
-			log.Println("refreshing contracts:", err)
-			time.Sleep(time.Second * 30)
-			continue
-		}
-
-		waitInterval(time.Minute * 10)
-	}
+// syncJob a sync that runs on an interval with the host retrieved at the
+// start of the tick. Jobs run concurrently so a slow sync does not delay the
+// others.
+type syncJob struct {
+	name     string
+	interval time.Duration
+	fn       func(api.HostGET) error
+	// unavailable is called instead of fn if the host could not be retrieved
+	unavailable func()
+
+	mu      sync.Mutex
+	running bool
+	next    time.Time
 }
 
-func refreshConnectivity() {
-	for {
-		if err := syncHostConnectivity(); err != nil {
-			log.Println("refreshing connectivity:", err)
-			time.Sleep(time.Second * 30)
-			continue
-		}
-
-		waitInterval(time.Minute * 10)
+var (
+	contractsJob = &syncJob{
+		name:     "contracts",
+		interval: contractsInterval,
+		fn:       syncContracts,
 	}
-}
-
-func refreshStatus() {
-	for {
-		if err := syncHostStatus(); err != nil {
-			log.Println("refreshing status:", err)
-			time.Sleep(time.Second * 30)
-			continue
-		}
-
-		waitInterval(time.Second * 10)
+	connectivityJob = &syncJob{
+		name:        "connectivity",
+		interval:    connectivityInterval,
+		fn:          syncHostConnectivity,
+		unavailable: connectivityUnavailable,
+	}
+	statusJob = &syncJob{
+		name:        "status",
+		interval:    statusInterval,
+		fn:          syncHostStatus,
+		unavailable: statusUnavailable,
 	}
-}
-
-func getBandwidthUsage() (upload, download uint64) {
-	bandwidthMu.Lock()
-	defer bandwidthMu.Unlock()
 
-	bw, err := apiClient.HostBandwidthGet()
+	syncJobs = []*syncJob{contractsJob, connectivityJob, statusJob}
+)
 
+// nextSync returns when a sync should run next. Failed syncs are retried
+// after 30 seconds.
+func nextSync(name string, interval time.Duration, err error) time.Time {
 	if err != nil {
-		log.Printf("warn: unable to retrieve bandwidth: %s", err)
-		return
+		logger.Error("unable to refresh "+name, "error", err)
+		return time.Now().Add(retryInterval)
 	}
 
-	dUp := bw.Upload
-	dDown := bw.Download
+	return time.Now().Add(interval).Truncate(interval)
+}
 
-	if dUp >= counters.lastUpload {
-		dUp -= counters.lastUpload
-	}
+// start marks the job as running and returns true if it is due and not
+// already running
+func (j *syncJob) start(now time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
 
-	if dDown >= counters.lastDownload {
-		dDown -= counters.lastDownload
+	if j.running || now.Before(j.next) {
+		return false
 	}
 
-	upload = counters.totalUpload + dUp
-	download = counters.totalDownload + dDown
+	j.running = true
+	return true
+}
 
-	counters.totalUpload = upload
-	counters.totalDownload = download
-	counters.lastUpload = bw.Upload
-	counters.lastDownload = bw.Download
+// finish schedules the job's next run
+func (j *syncJob) finish(err error) {
+	next := nextSync(j.name, j.interval, err)
 
-	return
+	j.mu.Lock()
+	j.running = false
+	j.next = next
+	j.mu.Unlock()
 }
 
-// initializes the bandwidth counters from the database to count the total bandwidth usage of the
-// host. This will cause us to lose any existing bytes on initialization, but should prevent counting
-// bandwidth twice
-func initBandwidthCounters() {
-	bandwidthMu.Lock()
-	defer bandwidthMu.Unlock()
+// refresh retrieves the host from the Sia API once at the start of each tick
+// and starts the syncs that are due
+func refresh() {
+	for {
+		waitInterval(statusInterval)
 
-	meta, err := persist.GetLastMetadata()
-	if err != nil {
-		log.Printf("warn: unable to load bandwidth: %s", err)
-		return
-	}
+		// alerts raised by the syncs that completed since the last tick
+		recordAlerts()
 
-	bw, err := apiClient.HostBandwidthGet()
+		now := time.Now()
+		host, err := apiClient.HostGet()
+		if err != nil {
+			err = fmt.Errorf("get host: %w", err)
+		}
 
-	if err != nil {
-		log.Printf("warn: unable to retrieve bandwidth: %s", err)
-		return
+		for _, job := range syncJobs {
+			if !job.start(now) {
+				continue
+			}
+
+			if err != nil {
+				if job.unavailable != nil {
+					job.unavailable()
+				}
+				job.finish(err)
+				continue
+			}
+
+			go func(job *syncJob) {
+				job.finish(job.fn(host))
+			}(job)
+		}
 	}
-
-	counters = new(bandwidthCounters)
-	counters.totalUpload = meta.UploadBandwidth
-	counters.totalDownload = meta.DownloadBandwidth
-	counters.lastUpload = bw.Upload
-	counters.lastDownload = bw.Download
 }
 
 //Start begins syncing data from Sia
-func Start(siaAddr string) error {
+func Start(opts Options) error {
+	options = opts
+	logger = opts.Log
 	apiClient = siaapi.NewUnsafeClient(siaapi.Client{
 		Options: siaapi.Options{
-			Address:   siaAddr,
+			Address:   opts.SiaAddress,
 			UserAgent: "Sia-Agent",
 		},
 	})
 
 	initBandwidthCounters()
 
-	if err := syncContracts(); err != nil {
-		return fmt.Errorf("refreshing contracts: %w", err)
+	host, err := apiClient.HostGet()
+	if err != nil {
+		statusUnavailable()
+		connectivityUnavailable()
+		return fmt.Errorf("get host: %w", err)
 	}
 
-	if err := syncHostConnectivity(); err != nil {
-		log.Println(fmt.Errorf("refreshing connectivity: %w", err))
+	if err := syncContracts(host); err != nil {
+		return fmt.Errorf("refreshing contracts: %w", err)
 	}
+	contractsJob.finish(nil)
 
-	if err := syncHostStatus(); err != nil {
+	connectivityJob.finish(syncHostConnectivity(host))
+
+	if err := syncHostStatus(host); err != nil {
 		return fmt.Errorf("refreshing status: %w", err)
 	}
+	statusJob.finish(nil)
+
+	recordAlerts()
 
-	go refreshContracts()
-	go refreshStatus()
-	go refreshConnectivity()
+	go refresh()
 
 	return nil
 }
@@ -6,6 +6,14 @@ var (
 	// AlertSyncError alerts the user there was an issue syncing the status
 	AlertSyncError = types.HostAlertID("hostAlertSyncError")
 
+	// AlertContractSyncError alerts the user there was an issue syncing the
+	// contracts
+	AlertContractSyncError = types.HostAlertID("hostAlertContractSyncError")
+
+	// AlertConnectivitySyncError alerts the user there was an issue checking
+	// the host's connectivity
+	AlertConnectivitySyncError = types.HostAlertID("hostAlertConnectivitySyncError")
+
 	// AlertFolderReadWriteError alerts the user to read and/or write failures from the host
 	AlertFolderReadWriteError = types.HostAlertID("hostAlertFolderError")
 
@@ -21,6 +29,14 @@ var (
 	// AlertCollateralBudget alerts the user that the collateral budget is almost fully utilized
 	AlertCollateralBudget = types.HostAlertID("hostAlertCollateralBudgetUtilization")
 
+	// AlertStorageCapacity alerts the user that the host or a storage folder is
+	// projected to be full soon
+	AlertStorageCapacity = types.HostAlertID("hostAlertStorageCapacity")
+
+	// AlertProofDeadline alerts the user that unresolved contracts are
+	// approaching their proof deadline without a confirmed storage proof
+	AlertProofDeadline = types.HostAlertID("hostAlertProofDeadline")
+
 	// AlertConnectionStatus alerts the user that a connection issue occurred
 	AlertConnectionStatus = types.HostAlertID("hostAlertConnectionStatus")
 )
@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+type (
+	// BandwidthUsage the number of bytes uploaded and downloaded by the host
+	// during a period
+	BandwidthUsage struct {
+		Upload    uint64    `json:"upload"`
+		Download  uint64    `json:"download"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// BandwidthCounters the host's last bandwidth counter reading and the
+	// total bandwidth counted by the dashboard
+	BandwidthCounters struct {
+		LastUpload    uint64 `json:"last_upload"`
+		LastDownload  uint64 `json:"last_download"`
+		TotalUpload   uint64 `json:"total_upload"`
+		TotalDownload uint64 `json:"total_download"`
+		// StartTime the time Sia started counting, used to detect restarts
+		StartTime time.Time `json:"start_time"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// BandwidthRate the host's bandwidth usage and average throughput in
+	// bytes per second during a period
+	BandwidthRate struct {
+		BandwidthUsage
+		UploadRate   float64 `json:"upload_rate"`
+		DownloadRate float64 `json:"download_rate"`
+	}
+)
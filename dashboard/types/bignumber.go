@@ -66,3 +66,15 @@ func (b BigNumber) Div64(a uint64) (c BigNumber) {
 
 	return c
 }
+
+//IsZero returns true if the BigNumber is zero
+func (b BigNumber) IsZero() bool {
+	return b.i.Sign() == 0
+}
+
+//Float64 returns the nearest float64 value of the BigNumber
+func (b BigNumber) Float64() float64 {
+	f, _ := new(big.Float).SetInt(&b.i).Float64()
+
+	return f
+}
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+type (
+	// CapacityEstimate the projected time until a host or storage folder is
+	// full. DaysUntilFull is -1 and FullTimestamp is zero if used storage is
+	// not growing.
+	CapacityEstimate struct {
+		Path          string    `json:"path,omitempty"`
+		Capacity      uint64    `json:"capacity"`
+		UsedStorage   uint64    `json:"used_storage"`
+		GrowthPerDay  float64   `json:"growth_per_day"`
+		DaysUntilFull float64   `json:"days_until_full"`
+		FullTimestamp time.Time `json:"full_timestamp"`
+	}
+
+	// CapacityPlan the projected time until the host and each of its storage
+	// folders are full
+	CapacityPlan struct {
+		Host      CapacityEstimate   `json:"host"`
+		Folders   []CapacityEstimate `json:"folders"`
+		Timestamp time.Time          `json:"timestamp"`
+	}
+)
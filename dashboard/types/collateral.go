@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// CollateralUsage the host's locked, risked, and budgeted collateral at
+	// a point in time
+	CollateralUsage struct {
+		Locked    siatypes.Currency `json:"locked"`
+		Risked    siatypes.Currency `json:"risked"`
+		Budget    siatypes.Currency `json:"budget"`
+		Timestamp time.Time         `json:"timestamp"`
+	}
+
+	// CollateralUnlock the collateral projected to unlock on a day as
+	// unresolved contracts expire
+	CollateralUnlock struct {
+		Timestamp       time.Time         `json:"timestamp"`
+		Contracts       uint64            `json:"contracts"`
+		Unlocked        siatypes.Currency `json:"unlocked"`
+		RemainingLocked siatypes.Currency `json:"remaining_locked"`
+	}
+)
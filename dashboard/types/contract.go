@@ -0,0 +1,89 @@
+package types
+
+import (
+	"time"
+
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// ContractStatusSucceeded the contract's storage proof was confirmed or
+	// no proof was required
+	ContractStatusSucceeded = "obligationSucceeded"
+	// ContractStatusFailed the contract's proof deadline passed without a
+	// confirmed storage proof
+	ContractStatusFailed = "obligationFailed"
+	// ContractStatusUnresolved the contract is still active or waiting for a
+	// storage proof
+	ContractStatusUnresolved = "obligationUnresolved"
+
+	// ContractEventCreated a new contract was synced
+	ContractEventCreated = "created"
+	// ContractEventStatus the contract's status changed
+	ContractEventStatus = "status"
+	// ContractEventUpdated the contract changed without changing its status
+	ContractEventUpdated = "updated"
+	// ContractEventRemoved the contract is no longer returned by the host
+	ContractEventRemoved = "removed"
+)
+
+type (
+	// HostContract merges fields from the host's contract db and the blockchain
+	HostContract struct {
+		ID                     string            `json:"id"`
+		BlockID                string            `json:"blockID"`
+		TransactionID          string            `json:"transactionID"`
+		MerkleRoot             string            `json:"merkleRoot"`
+		UnlockHash             string            `json:"unlockHash"`
+		Status                 string            `json:"status"`
+		RevisionNumber         uint64            `json:"revisionNumber"`
+		NegotiationHeight      uint64            `json:"negotiationHeight"`
+		ExpirationHeight       uint64            `json:"expirationHeight"`
+		ProofDeadline          uint64            `json:"proofDeadline"`
+		ProofHeight            uint64            `json:"proofHeight"`
+		SyncedHeight           uint64            `json:"syncedHeight"`
+		DataSize               uint64            `json:"fileSize"`
+		ProofConfirmed         bool              `json:"proofConfirmed"`
+		NegotiationTimestamp   time.Time         `json:"negotiationTimestamp"`
+		ExpirationTimestamp    time.Time         `json:"expirationTimestamp"`
+		ProofDeadlineTimestamp time.Time         `json:"proofDeadlineTimestamp"`
+		ProofTimestamp         time.Time         `json:"proofTimestamp"`
+		Payout                 siatypes.Currency `json:"payout"`
+		LockedCollateral       siatypes.Currency `json:"lockedCollateral"`
+		RiskedCollateral       siatypes.Currency `json:"riskedCollateral"`
+		PotentialRevenue       siatypes.Currency `json:"potentialRevenue"`
+		EarnedRevenue          BigNumber         `json:"earnedRevenue"`
+		LostRevenue            siatypes.Currency `json:"lostRevenue"`
+		BurntCollateral        siatypes.Currency `json:"burntCollateral"`
+		Revenue                RevenueBreakdown  `json:"revenue"`
+	}
+
+	// ContractRisk an unresolved contract whose proof window is open without
+	// a confirmed storage proof
+	ContractRisk struct {
+		ContractID             string            `json:"contract_id"`
+		ExpirationHeight       uint64            `json:"expiration_height"`
+		ProofDeadline          uint64            `json:"proof_deadline"`
+		BlocksRemaining        uint64            `json:"blocks_remaining"`
+		ProofDeadlineTimestamp time.Time         `json:"proof_deadline_timestamp"`
+		LockedCollateral       siatypes.Currency `json:"locked_collateral"`
+		RiskedCollateral       siatypes.Currency `json:"risked_collateral"`
+		PotentialRevenue       siatypes.Currency `json:"potential_revenue"`
+		Severity               string            `json:"severity"`
+	}
+
+	// ContractEvent records a change to a contract
+	ContractEvent struct {
+		ContractID     string    `json:"contract_id"`
+		Type           string    `json:"type"`
+		PreviousStatus string    `json:"previous_status"`
+		Status         string    `json:"status"`
+		Timestamp      time.Time `json:"timestamp"`
+
+		// Previous and Current the contract before and after the change,
+		// only stored until the change is applied to the snapshots. Previous
+		// is nil for created contracts, Current is nil for removed contracts.
+		Previous *HostContract `json:"previous,omitempty"`
+		Current  *HostContract `json:"current,omitempty"`
+	}
+)
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"time"
+
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// ForecastMonth the projected revenue, contracts, and storage for a
+	// single month. Low and high values are the bounds of the confidence band.
+	ForecastMonth struct {
+		Month              time.Time         `json:"month"`
+		ExpiringContracts  uint64            `json:"expiring_contracts"`
+		UnresolvedRevenue  siatypes.Currency `json:"unresolved_revenue"`
+		ExpectedRevenue    siatypes.Currency `json:"expected_revenue"`
+		RevenueLow         siatypes.Currency `json:"revenue_low"`
+		RevenueHigh        siatypes.Currency `json:"revenue_high"`
+		NewContracts       uint64            `json:"new_contracts"`
+		NewContractsLow    uint64            `json:"new_contracts_low"`
+		NewContractsHigh   uint64            `json:"new_contracts_high"`
+		UsedStorage        uint64            `json:"used_storage"`
+		UsedStorageLow     uint64            `json:"used_storage_low"`
+		UsedStorageHigh    uint64            `json:"used_storage_high"`
+		StorageUtilization float64           `json:"storage_utilization"`
+	}
+
+	// HostForecast projected revenue and growth of the host
+	HostForecast struct {
+		SuccessRate        float64           `json:"success_rate"`
+		AverageRevenue     siatypes.Currency `json:"average_revenue"`
+		AverageDuration    time.Duration     `json:"average_duration"`
+		TotalStorage       uint64            `json:"total_storage"`
+		ConfidenceInterval float64           `json:"confidence_interval"`
+		Months             []ForecastMonth   `json:"months"`
+	}
+)
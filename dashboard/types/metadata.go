@@ -19,21 +19,75 @@ type (
 		UploadBandwidthPrice   siatypes.Currency `json:"upload_price"`
 	}
 
+	// HostFinancialMetrics the host's financial metrics reported by Sia
+	HostFinancialMetrics struct {
+		AccountFunding                    siatypes.Currency `json:"account_funding"`
+		PotentialAccountFunding           siatypes.Currency `json:"potential_account_funding"`
+		ContractCount                     uint64            `json:"contract_count"`
+		ContractCompensation              siatypes.Currency `json:"contract_compensation"`
+		PotentialContractCompensation     siatypes.Currency `json:"potential_contract_compensation"`
+		LockedStorageCollateral           siatypes.Currency `json:"locked_storage_collateral"`
+		LostRevenue                       siatypes.Currency `json:"lost_revenue"`
+		LostStorageCollateral             siatypes.Currency `json:"lost_storage_collateral"`
+		PotentialStorageRevenue           siatypes.Currency `json:"potential_storage_revenue"`
+		RiskedStorageCollateral           siatypes.Currency `json:"risked_storage_collateral"`
+		StorageRevenue                    siatypes.Currency `json:"storage_revenue"`
+		TransactionFeeExpenses            siatypes.Currency `json:"transaction_fee_expenses"`
+		DownloadBandwidthRevenue          siatypes.Currency `json:"download_bandwidth_revenue"`
+		PotentialDownloadBandwidthRevenue siatypes.Currency `json:"potential_download_bandwidth_revenue"`
+		PotentialUploadBandwidthRevenue   siatypes.Currency `json:"potential_upload_bandwidth_revenue"`
+		UploadBandwidthRevenue            siatypes.Currency `json:"upload_bandwidth_revenue"`
+	}
+
+	// HostNetworkMetrics the number of RPC calls handled by the host since
+	// Sia was started
+	HostNetworkMetrics struct {
+		DownloadCalls     uint64 `json:"download_calls"`
+		ErrorCalls        uint64 `json:"error_calls"`
+		FormContractCalls uint64 `json:"form_contract_calls"`
+		RenewCalls        uint64 `json:"renew_calls"`
+		ReviseCalls       uint64 `json:"revise_calls"`
+		SettingsCalls     uint64 `json:"settings_calls"`
+		UnrecognizedCalls uint64 `json:"unrecognized_calls"`
+	}
+
+	// HostMetrics the host's financial and network metrics at a point in time
+	HostMetrics struct {
+		FinancialMetrics HostFinancialMetrics `json:"financial_metrics"`
+		NetworkMetrics   HostNetworkMetrics   `json:"network_metrics"`
+		Timestamp        time.Time            `json:"timestamp"`
+	}
+
+	// StorageFolder the usage of one of the host's storage folders
+	StorageFolder struct {
+		Path        string `json:"path"`
+		Capacity    uint64 `json:"capacity"`
+		UsedStorage uint64 `json:"used_storage"`
+	}
+
 	//HostMeta a snapshot of a host at a point in time
 	HostMeta struct {
-		ActiveContracts     uint64            `json:"active_contracts"`
-		SuccessfulContracts uint64            `json:"successful_contracts"`
-		FailedContracts     uint64            `json:"failed_contracts"`
-		UsedStorage         uint64            `json:"used_storage"`
-		TotalStorage        uint64            `json:"total_storage"`
-		UploadBandwidth     uint64            `json:"upload_bandwidth"`
-		DownloadBandwidth   uint64            `json:"download_bandwidth"`
-		Payout              siatypes.Currency `json:"payout"`
-		EarnedRevenue       BigNumber         `json:"earned_revenue"`
-		PotentialRevenue    siatypes.Currency `json:"potential_revenue"`
-		BurntCollateral     siatypes.Currency `json:"burnt_collateral"`
-		Settings            HostSettings      `json:"host_settings"`
-		FirstSeen           time.Time         `json:"first_seen"`
-		Timestamp           time.Time         `json:"timestamp"`
+		ActiveContracts     uint64               `json:"active_contracts"`
+		SuccessfulContracts uint64               `json:"successful_contracts"`
+		FailedContracts     uint64               `json:"failed_contracts"`
+		UsedStorage         uint64               `json:"used_storage"`
+		TotalStorage        uint64               `json:"total_storage"`
+		Folders             []StorageFolder      `json:"folders"`
+		UploadBandwidth     uint64               `json:"upload_bandwidth"`
+		DownloadBandwidth   uint64               `json:"download_bandwidth"`
+		Payout              siatypes.Currency    `json:"payout"`
+		EarnedRevenue       BigNumber            `json:"earned_revenue"`
+		PotentialRevenue    siatypes.Currency    `json:"potential_revenue"`
+		BurntCollateral     siatypes.Currency    `json:"burnt_collateral"`
+		LockedCollateral    siatypes.Currency    `json:"locked_collateral"`
+		RiskedCollateral    siatypes.Currency    `json:"risked_collateral"`
+		CollateralBudget    siatypes.Currency    `json:"collateral_budget"`
+		EarnedBreakdown     RevenueBreakdown     `json:"earned_breakdown"`
+		PotentialBreakdown  RevenueBreakdown     `json:"potential_breakdown"`
+		Settings            HostSettings         `json:"host_settings"`
+		FinancialMetrics    HostFinancialMetrics `json:"financial_metrics"`
+		NetworkMetrics      HostNetworkMetrics   `json:"network_metrics"`
+		FirstSeen           time.Time            `json:"first_seen"`
+		Timestamp           time.Time            `json:"timestamp"`
 	}
 )
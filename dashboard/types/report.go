@@ -0,0 +1,39 @@
+package types
+
+import (
+	"time"
+
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// ReportDaily a report covering a single day
+	ReportDaily = "daily"
+	// ReportWeekly a report covering a week starting on Monday
+	ReportWeekly = "weekly"
+)
+
+type (
+	// HostReport a summary of the host's activity over a period
+	HostReport struct {
+		ID                  string            `json:"id"`
+		Period              string            `json:"period"`
+		EarnedRevenue       BigNumber         `json:"earned_revenue"`
+		PotentialRevenue    siatypes.Currency `json:"potential_revenue"`
+		BurntCollateral     siatypes.Currency `json:"burnt_collateral"`
+		ActiveContracts     uint64            `json:"active_contracts"`
+		NewContracts        uint64            `json:"new_contracts"`
+		ExpiredContracts    uint64            `json:"expired_contracts"`
+		SuccessfulContracts uint64            `json:"successful_contracts"`
+		FailedContracts     uint64            `json:"failed_contracts"`
+		UsedStorage         uint64            `json:"used_storage"`
+		TotalStorage        uint64            `json:"total_storage"`
+		StorageGrowth       int64             `json:"storage_growth"`
+		UploadBandwidth     uint64            `json:"upload_bandwidth"`
+		DownloadBandwidth   uint64            `json:"download_bandwidth"`
+		Alerts              []HostAlert       `json:"alerts"`
+		Start               time.Time         `json:"start"`
+		End                 time.Time         `json:"end"`
+		Generated           time.Time         `json:"generated"`
+	}
+)
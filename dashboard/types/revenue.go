@@ -0,0 +1,58 @@
+package types
+
+import (
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// RevenueBreakdown revenue split by income type. Account funding is
+	// deposited into ephemeral accounts by renters to pay for RPCs.
+	RevenueBreakdown struct {
+		ContractRevenue siatypes.Currency `json:"contract_revenue"`
+		StorageRevenue  siatypes.Currency `json:"storage_revenue"`
+		UploadRevenue   siatypes.Currency `json:"upload_revenue"`
+		DownloadRevenue siatypes.Currency `json:"download_revenue"`
+		AccountFunding  siatypes.Currency `json:"account_funding"`
+	}
+)
+
+// Add adds each income type and returns the result
+func (r RevenueBreakdown) Add(a RevenueBreakdown) RevenueBreakdown {
+	return RevenueBreakdown{
+		ContractRevenue: r.ContractRevenue.Add(a.ContractRevenue),
+		StorageRevenue:  r.StorageRevenue.Add(a.StorageRevenue),
+		UploadRevenue:   r.UploadRevenue.Add(a.UploadRevenue),
+		DownloadRevenue: r.DownloadRevenue.Add(a.DownloadRevenue),
+		AccountFunding:  r.AccountFunding.Add(a.AccountFunding),
+	}
+}
+
+// Sub subtracts each income type and returns the result. ok is false if any
+// income type would become negative.
+func (r RevenueBreakdown) Sub(a RevenueBreakdown) (_ RevenueBreakdown, ok bool) {
+	if r.ContractRevenue.Cmp(a.ContractRevenue) < 0 || r.StorageRevenue.Cmp(a.StorageRevenue) < 0 ||
+		r.UploadRevenue.Cmp(a.UploadRevenue) < 0 || r.DownloadRevenue.Cmp(a.DownloadRevenue) < 0 ||
+		r.AccountFunding.Cmp(a.AccountFunding) < 0 {
+		return r, false
+	}
+
+	return RevenueBreakdown{
+		ContractRevenue: r.ContractRevenue.Sub(a.ContractRevenue),
+		StorageRevenue:  r.StorageRevenue.Sub(a.StorageRevenue),
+		UploadRevenue:   r.UploadRevenue.Sub(a.UploadRevenue),
+		DownloadRevenue: r.DownloadRevenue.Sub(a.DownloadRevenue),
+		AccountFunding:  r.AccountFunding.Sub(a.AccountFunding),
+	}, true
+}
+
+// Equals returns true if every income type is equal
+func (r RevenueBreakdown) Equals(a RevenueBreakdown) bool {
+	return r.ContractRevenue.Equals(a.ContractRevenue) && r.StorageRevenue.Equals(a.StorageRevenue) &&
+		r.UploadRevenue.Equals(a.UploadRevenue) && r.DownloadRevenue.Equals(a.DownloadRevenue) &&
+		r.AccountFunding.Equals(a.AccountFunding)
+}
+
+// IsZero returns true if every income type is zero
+func (r RevenueBreakdown) IsZero() bool {
+	return r.Equals(RevenueBreakdown{})
+}
@@ -0,0 +1,13 @@
+package types
+
+import "time"
+
+type (
+	// ShareToken grants public read-only access to a selection of the
+	// host's stats
+	ShareToken struct {
+		Token   string    `json:"token"`
+		Fields  []string  `json:"fields"`
+		Created time.Time `json:"created"`
+	}
+)
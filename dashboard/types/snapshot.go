@@ -18,6 +18,8 @@ type (
 		EarnedRevenue       BigNumber         `json:"earned_revenue"`
 		PotentialRevenue    siatypes.Currency `json:"potential_revenue"`
 		BurntCollateral     siatypes.Currency `json:"burnt_collateral"`
+		EarnedBreakdown     RevenueBreakdown  `json:"earned_breakdown"`
+		PotentialBreakdown  RevenueBreakdown  `json:"potential_breakdown"`
 		Timestamp           time.Time         `json:"timestamp"`
 	}
 )
@@ -13,6 +13,13 @@ type (
 		Severity string `json:"severity"`
 	}
 
+	// AlertOccurrence an alert that was active during an hour. Timestamp is
+	// when the alert was first seen in that hour.
+	AlertOccurrence struct {
+		HostAlert
+		Timestamp time.Time `json:"timestamp"`
+	}
+
 	// HostStatus status information about the host
 	HostStatus struct {
 		HostMeta
@@ -21,6 +28,7 @@ type (
 		WalletUnlocked     bool      `json:"wallet_unlocked"`
 		Version            string    `json:"version"`
 		StorageDelta       int64     `json:"storage_delta"`
+		DaysUntilFull      float64   `json:"days_until_full"`
 		StartTime          time.Time `json:"start_time"`
 	}
 )
@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+type (
+	// ConnectivityCheck the result of a single connectivity check
+	ConnectivityCheck struct {
+		Reachable          bool `json:"reachable"`
+		AcceptingContracts bool `json:"accepting_contracts"`
+		SiaReachable       bool `json:"sia_reachable"`
+		// Latency the time taken to connect to the host in milliseconds
+		Latency   uint64    `json:"latency"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// Outage a period where the host was not reachable
+	Outage struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	}
+)
+
+// Online returns true if the host was reachable during the check
+func (c ConnectivityCheck) Online() bool {
+	return c.SiaReachable && c.Reachable
+}
@@ -0,0 +1,26 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+// APIStatsResponse the API usage metrics of each endpoint
+type APIStatsResponse struct {
+	router.APIResponse
+	Started   time.Time              `json:"started"`
+	Endpoints []router.EndpointStats `json:"endpoints"`
+}
+
+func handleGetAPIStats(w http.ResponseWriter, r *router.APIRequest) error {
+	return router.SendJSONResponse(APIStatsResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully retrieved stats",
+			Type:    "success",
+		},
+		Started:   started,
+		Endpoints: apiRouter.Stats(),
+	}, 200, w, r)
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+type (
+	// HostBandwidthResponse the host's bandwidth usage grouped by period
+	HostBandwidthResponse struct {
+		HostResponse
+		Granularity string                `json:"granularity"`
+		Bandwidth   []types.BandwidthRate `json:"bandwidth"`
+	}
+)
+
+// periodStart returns the start of the period containing the timestamp
+func periodStart(timestamp time.Time, granularity string) time.Time {
+	timestamp = timestamp.UTC()
+	day := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch granularity {
+	case "day":
+		return day
+	case "week":
+		// weeks start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(timestamp.Year(), timestamp.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return timestamp.Truncate(time.Hour)
+	}
+}
+
+// periodEnd returns the end of the period starting at the timestamp
+func periodEnd(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case "day":
+		return start.AddDate(0, 0, 1)
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.Add(time.Hour)
+	}
+}
+
+// bytesPerSecond returns the average throughput during the period. The
+// current period's average only includes the time that has already passed.
+func bytesPerSecond(n uint64, start, end, current time.Time) float64 {
+	if end.After(current) {
+		end = current
+	}
+
+	seconds := end.Sub(start).Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+
+	return float64(n) / seconds
+}
+
+func handleGetBandwidth(w http.ResponseWriter, r *router.APIRequest) error {
+	current := time.Now().UTC()
+	timestamps := parseTimeParams(r, "start", "end")
+	start, end := timestamps[0], timestamps[1]
+
+	granularity := r.Request.URL.Query().Get("granularity")
+	switch granularity {
+	case "":
+		granularity = "hour"
+	case "hour", "day", "week", "month":
+	default:
+		return router.InvalidParameter("granularity must be hour, day, week, or month")
+	}
+
+	if end.IsZero() {
+		end = current
+	}
+
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	if end.Before(start) {
+		return router.InvalidParameter("end must be after start")
+	}
+
+	usage, err := persist.GetBandwidthUsage(start, end)
+	if err != nil {
+		return router.StorageError("unable to retrieve bandwidth", err)
+	}
+
+	resp := HostBandwidthResponse{
+		HostResponse: HostResponse{
+			APIResponse: router.APIResponse{
+				Message: "successfully retrieved bandwidth",
+				Type:    "success",
+			},
+			Start: start,
+			End:   end,
+		},
+		Granularity: granularity,
+		Bandwidth:   []types.BandwidthRate{},
+	}
+
+	for _, hour := range usage {
+		timestamp := periodStart(hour.Timestamp, granularity)
+		i := len(resp.Bandwidth) - 1
+
+		if i < 0 || !resp.Bandwidth[i].Timestamp.Equal(timestamp) {
+			resp.Bandwidth = append(resp.Bandwidth, types.BandwidthRate{
+				BandwidthUsage: types.BandwidthUsage{
+					Timestamp: timestamp,
+				},
+			})
+			i++
+		}
+
+		resp.Bandwidth[i].Upload += hour.Upload
+		resp.Bandwidth[i].Download += hour.Download
+	}
+
+	for i, period := range resp.Bandwidth {
+		end := periodEnd(period.Timestamp, granularity)
+
+		resp.Bandwidth[i].UploadRate = bytesPerSecond(period.Upload, period.Timestamp, end, current)
+		resp.Bandwidth[i].DownloadRate = bytesPerSecond(period.Download, period.Timestamp, end, current)
+	}
+
+	return router.SendJSONResponse(resp, 200, w, r)
+}
@@ -0,0 +1,27 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+type (
+	// HostCapacityResponse the host's capacity plan
+	HostCapacityResponse struct {
+		router.APIResponse
+		Capacity types.CapacityPlan `json:"capacity"`
+	}
+)
+
+func handleGetCapacity(w http.ResponseWriter, r *router.APIRequest) error {
+	return router.SendJSONResponse(HostCapacityResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully retrieved capacity plan",
+			Type:    "success",
+		},
+		Capacity: cache.GetCapacityPlan(),
+	}, 200, w, r)
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/forecast"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+type (
+	// HostCollateralResponse the host's collateral usage over time
+	HostCollateralResponse struct {
+		HostResponse
+		Current  types.CollateralUsage    `json:"current"`
+		History  []types.CollateralUsage  `json:"history"`
+		Timeline []types.CollateralUnlock `json:"timeline"`
+	}
+)
+
+func collateralUsage(meta types.HostMeta) types.CollateralUsage {
+	return types.CollateralUsage{
+		Locked:    meta.LockedCollateral,
+		Risked:    meta.RiskedCollateral,
+		Budget:    meta.CollateralBudget,
+		Timestamp: meta.Timestamp,
+	}
+}
+
+func handleGetCollateral(w http.ResponseWriter, r *router.APIRequest) error {
+	current := time.Now().UTC()
+	timestamps := parseTimeParams(r, "start", "end")
+	start, end := timestamps[0], timestamps[1]
+
+	if end.IsZero() {
+		end = current
+	}
+
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	if end.Before(start) {
+		return router.InvalidParameter("end must be after start")
+	}
+
+	metadata, err := persist.GetHostMetadata(start, end)
+	if err != nil {
+		return router.StorageError("unable to retrieve metadata", err)
+	}
+
+	lastMetadata, err := persist.GetLastMetadata()
+	if err != nil {
+		return router.StorageError("unable to retrieve metadata", err)
+	}
+
+	contracts, err := persist.GetContracts()
+	if err != nil {
+		return router.StorageError("unable to retrieve contracts", err)
+	}
+
+	resp := HostCollateralResponse{
+		HostResponse: HostResponse{
+			APIResponse: router.APIResponse{
+				Message: "successfully retrieved collateral",
+				Type:    "success",
+			},
+			Start: start,
+			End:   end,
+		},
+		Current:  collateralUsage(lastMetadata),
+		Timeline: forecast.CollateralTimeline(contracts, current),
+	}
+
+	for _, meta := range metadata {
+		resp.History = append(resp.History, collateralUsage(meta))
+	}
+
+	return router.SendJSONResponse(resp, 200, w, r)
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/cache"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+	siatypes "gitlab.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// ContractsAtRiskResponse the contracts whose proof deadline is approaching
+	ContractsAtRiskResponse struct {
+		router.APIResponse
+		Contracts        []types.ContractRisk `json:"contracts"`
+		LockedCollateral siatypes.Currency    `json:"locked_collateral"`
+		RiskedCollateral siatypes.Currency    `json:"risked_collateral"`
+		PotentialRevenue siatypes.Currency    `json:"potential_revenue"`
+	}
+)
+
+func handleGetContractsAtRisk(w http.ResponseWriter, r *router.APIRequest) error {
+	resp := ContractsAtRiskResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully retrieved contracts at risk",
+			Type:    "success",
+		},
+		Contracts: cache.GetContractsAtRisk(),
+	}
+
+	for _, contract := range resp.Contracts {
+		resp.LockedCollateral = resp.LockedCollateral.Add(contract.LockedCollateral)
+		resp.RiskedCollateral = resp.RiskedCollateral.Add(contract.RiskedCollateral)
+		resp.PotentialRevenue = resp.PotentialRevenue.Add(contract.PotentialRevenue)
+	}
+
+	return router.SendJSONResponse(resp, 200, w, r)
+}
@@ -1,27 +1,202 @@
 package web
 
-import "github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+import (
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+// timeRangeQuery the query parameters of endpoints that return data between
+// two timestamps
+var timeRangeQuery = []router.QueryParam{
+	{Name: "start", Type: "integer", Description: "unix timestamp, defaults to 30 days before end"},
+	{Name: "end", Type: "integer", Description: "unix timestamp, defaults to now"},
+}
 
 var endpoints = []router.APIEndpoint{
 	{
-		Name:    "Get Snapshots",
-		Method:  "GET",
-		Pattern: "/snapshots",
-		Secure:  false,
-		Handler: handleGetHostSnapshots,
+		Name:        "Get Snapshots",
+		Method:      "GET",
+		Pattern:     "/snapshots",
+		Secure:      false,
+		Handler:     handleGetHostSnapshots,
+		Description: "Returns the host's daily snapshots for the 16 months around the end date",
+		Query: []router.QueryParam{
+			{Name: "end", Type: "integer", Description: "unix timestamp, defaults to now"},
+		},
+		Response: HostSnapshotResponse{},
+	},
+	{
+		Name:        "Get Totals",
+		Method:      "GET",
+		Pattern:     "/totals",
+		Secure:      false,
+		Handler:     handleGetHostTotals,
+		Description: "Returns the host's totals for the day, month, and year of the date and its lifetime totals",
+		Query: []router.QueryParam{
+			{Name: "date", Type: "integer", Description: "unix timestamp, defaults to now"},
+		},
+		Response: HostTotalResponse{},
+	},
+	{
+		Name:        "Get Status",
+		Method:      "GET",
+		Pattern:     "/status",
+		Secure:      false,
+		Handler:     handleGetHostStatus,
+		Description: "Returns the host's current status and alerts",
+		Response:    HostStatusResponse{},
+	},
+	{
+		Name:        "Get Forecast",
+		Method:      "GET",
+		Pattern:     "/forecast",
+		Secure:      false,
+		Handler:     handleGetForecast,
+		Description: "Returns the host's projected revenue and storage",
+		Query: []router.QueryParam{
+			{Name: "months", Type: "integer", Description: "the number of months to forecast, between 1 and 24, defaults to 6"},
+		},
+		Response: HostForecastResponse{},
+	},
+	{
+		Name:        "Get Capacity",
+		Method:      "GET",
+		Pattern:     "/capacity",
+		Secure:      false,
+		Handler:     handleGetCapacity,
+		Description: "Returns the projected date the host and its storage folders will be full",
+		Response:    HostCapacityResponse{},
+	},
+	{
+		Name:        "Get Contracts At Risk",
+		Method:      "GET",
+		Pattern:     "/contracts/at-risk",
+		Secure:      false,
+		Handler:     handleGetContractsAtRisk,
+		Description: "Returns the contracts whose storage proof deadline is approaching",
+		Response:    ContractsAtRiskResponse{},
+	},
+	{
+		Name:        "Get Collateral",
+		Method:      "GET",
+		Pattern:     "/collateral",
+		Secure:      false,
+		Handler:     handleGetCollateral,
+		Description: "Returns the host's locked, risked, and burnt collateral over time",
+		Query:       timeRangeQuery,
+		Response:    HostCollateralResponse{},
+	},
+	{
+		Name:        "Get Metrics",
+		Method:      "GET",
+		Pattern:     "/metrics",
+		Secure:      false,
+		Handler:     handleGetMetrics,
+		Description: "Returns the host's hourly financial and network metrics",
+		Query:       timeRangeQuery,
+		Response:    HostMetricsResponse{},
 	},
 	{
-		Name:    "Get Totals",
-		Method:  "GET",
-		Pattern: "/totals",
-		Secure:  false,
-		Handler: handleGetHostTotals,
+		Name:        "Get Uptime",
+		Method:      "GET",
+		Pattern:     "/uptime",
+		Secure:      false,
+		Handler:     handleGetUptime,
+		Description: "Returns the host's uptime, connectivity checks, and outages",
+		Query:       timeRangeQuery,
+		Response:    HostUptimeResponse{},
 	},
 	{
-		Name:    "Get Status",
-		Method:  "GET",
-		Pattern: "/status",
-		Secure:  false,
-		Handler: handleGetHostStatus,
+		Name:        "Get Bandwidth",
+		Method:      "GET",
+		Pattern:     "/bandwidth",
+		Secure:      false,
+		Handler:     handleGetBandwidth,
+		Description: "Returns the host's bandwidth usage grouped by period",
+		Query: append([]router.QueryParam{
+			{Name: "granularity", Description: "the period to group usage by, defaults to hour", Enum: []string{"hour", "day", "week", "month"}},
+		}, timeRangeQuery...),
+		Response: HostBandwidthResponse{},
+	},
+	{
+		Name:        "Get Reports",
+		Method:      "GET",
+		Pattern:     "/reports",
+		Secure:      false,
+		Handler:     handleGetReports,
+		Description: "Returns the generated daily and weekly reports",
+		Response:    HostReportsResponse{},
+	},
+	{
+		Name:        "Get Report",
+		Method:      "GET",
+		Pattern:     "/reports/{id}",
+		Secure:      false,
+		Handler:     handleGetReport,
+		Description: "Returns a generated report as HTML, JSON, or markdown. The format query parameter selects the response's content type.",
+		Query: []router.QueryParam{
+			{Name: "format", Description: "defaults to html", Enum: []string{"html", "json", "md"}},
+		},
+		Response:     types.HostReport{},
+		ContentTypes: []string{"text/html", "application/json", "text/markdown"},
+	},
+	{
+		Name:        "Get Shares",
+		Method:      "GET",
+		Pattern:     "/shares",
+		Secure:      true,
+		Handler:     handleGetShares,
+		Description: "Returns the public share tokens and the fields that can be shared",
+		Response:    SharesResponse{},
+	},
+	{
+		Name:        "Create Share",
+		Method:      "POST",
+		Pattern:     "/shares",
+		Secure:      true,
+		Handler:     handleCreateShare,
+		Description: "Creates a public share token for the selected fields",
+		Request:     ShareRequest{},
+		Response:    ShareResponse{},
+		// creating shares is rare, limit it to prevent filling the
+		// database
+		RateLimit:    5,
+		RateInterval: time.Minute,
+	},
+	{
+		Name:        "Revoke Share",
+		Method:      "DELETE",
+		Pattern:     "/shares/{token}",
+		Secure:      true,
+		Handler:     handleRevokeShare,
+		Description: "Revokes a public share token",
+		Response:    router.APIResponse{},
+	},
+	{
+		Name:        "Get API Stats",
+		Method:      "GET",
+		Pattern:     "/admin/stats",
+		Secure:      true,
+		Handler:     handleGetAPIStats,
+		Description: "Returns the API's request counts, error counts, and latency histograms by endpoint",
+		Response:    APIStatsResponse{},
+	},
+}
+
+// shareEndpoints are served publicly under /share with a separate rate limit
+var shareEndpoints = []router.APIEndpoint{
+	{
+		Name:        "Get Shared Stats",
+		Method:      "GET",
+		Pattern:     "/{token}",
+		Secure:      false,
+		Handler:     handleGetSharedStats,
+		Description: "Returns the shared stats as an HTML page or JSON",
+		Query: []router.QueryParam{
+			{Name: "format", Description: "json to return the stats as JSON", Enum: []string{"json"}},
+		},
+		Response: SharedStatsResponse{},
 	},
 }
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/forecast"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+const (
+	defaultForecastMonths = 6
+	maxForecastMonths     = 24
+)
+
+type (
+	// HostForecastResponse the host's projected revenue and storage
+	HostForecastResponse struct {
+		router.APIResponse
+		Forecast types.HostForecast `json:"forecast"`
+	}
+)
+
+func handleGetForecast(w http.ResponseWriter, r *router.APIRequest) error {
+	months := defaultForecastMonths
+
+	if param := r.Request.URL.Query().Get("months"); len(param) != 0 {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 || n > maxForecastMonths {
+			return router.InvalidParameter("months must be between 1 and 24")
+		}
+
+		months = n
+	}
+
+	current := time.Now()
+
+	contracts, err := persist.GetContracts()
+	if err != nil {
+		return router.StorageError("unable to retrieve contracts", err)
+	}
+
+	snapshots, err := persist.GetHostSnapshots(current.AddDate(-1, 0, 0), current)
+	if err != nil {
+		return router.StorageError("unable to retrieve snapshots", err)
+	}
+
+	metadata, err := persist.GetHostMetadata(current.AddDate(0, 0, -90), current)
+	if err != nil {
+		return router.StorageError("unable to retrieve metadata", err)
+	}
+
+	return router.SendJSONResponse(HostForecastResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully retrieved forecast",
+			Type:    "success",
+		},
+		Forecast: forecast.Build(current, months, contracts, snapshots, metadata),
+	}, 200, w, r)
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+type (
+	// HostMetricsResponse the host's hourly financial and network metrics
+	HostMetricsResponse struct {
+		HostResponse
+		Metrics []types.HostMetrics `json:"metrics"`
+	}
+)
+
+func handleGetMetrics(w http.ResponseWriter, r *router.APIRequest) error {
+	timestamps := parseTimeParams(r, "start", "end")
+	start, end := timestamps[0], timestamps[1]
+
+	if end.IsZero() {
+		end = time.Now().UTC()
+	}
+
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	if end.Before(start) {
+		return router.InvalidParameter("end must be after start")
+	}
+
+	metadata, err := persist.GetHostMetadata(start, end)
+	if err != nil {
+		return router.StorageError("unable to retrieve metrics", err)
+	}
+
+	resp := HostMetricsResponse{
+		HostResponse: HostResponse{
+			APIResponse: router.APIResponse{
+				Message: "successfully retrieved metrics",
+				Type:    "success",
+			},
+			Start: start,
+			End:   end,
+		},
+		Metrics: make([]types.HostMetrics, 0, len(metadata)),
+	}
+
+	for _, meta := range metadata {
+		resp.Metrics = append(resp.Metrics, types.HostMetrics{
+			FinancialMetrics: meta.FinancialMetrics,
+			NetworkMetrics:   meta.NetworkMetrics,
+			Timestamp:        meta.Timestamp,
+		})
+	}
+
+	return router.SendJSONResponse(resp, 200, w, r)
+}
@@ -0,0 +1,68 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/siacentral/sia-host-dashboard/dashboard/report"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+type (
+	// HostReportsResponse the generated reports
+	HostReportsResponse struct {
+		router.APIResponse
+		Reports []types.HostReport `json:"reports"`
+	}
+)
+
+var reportContentTypes = map[string]string{
+	report.FormatJSON:     "application/json",
+	report.FormatHTML:     "text/html; charset=utf-8",
+	report.FormatMarkdown: "text/markdown; charset=utf-8",
+}
+
+func handleGetReports(w http.ResponseWriter, r *router.APIRequest) error {
+	reports, err := report.GetReports()
+	if err != nil {
+		return router.StorageError("unable to retrieve reports", err)
+	}
+
+	return router.SendJSONResponse(HostReportsResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully retrieved reports",
+			Type:    "success",
+		},
+		Reports: reports,
+	}, 200, w, r)
+}
+
+func handleGetReport(w http.ResponseWriter, r *router.APIRequest) error {
+	format := r.Request.URL.Query().Get("format")
+	if len(format) == 0 {
+		format = report.FormatHTML
+	}
+
+	contentType, exists := reportContentTypes[format]
+	if !exists {
+		return router.InvalidParameter("format must be json, html, or md")
+	}
+
+	buf, err := report.GetReport(mux.Vars(r.Request)["id"], format)
+	if errors.Is(err, report.ErrNotFound) {
+		return router.NotFound("report not found")
+	} else if err != nil {
+		return router.StorageError("unable to retrieve report", err)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(200)
+
+	if _, err := w.Write(buf); err != nil {
+		r.Log.Warn("unable to write response", "error", err)
+	}
+
+	return nil
+}
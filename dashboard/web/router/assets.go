@@ -0,0 +1,64 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/web"
+)
+
+// NormalizeBasePath returns the base path with a leading slash and without a
+// trailing slash. The root path is returned as an empty string.
+func NormalizeBasePath(basePath string) string {
+	basePath = strings.Trim(strings.TrimSpace(basePath), "/")
+	if len(basePath) == 0 {
+		return ""
+	}
+
+	return "/" + basePath
+}
+
+// indexHandler serves index.html with the base path injected so the web app
+// can resolve the API
+func indexHandler(basePath string) (http.Handler, error) {
+	f, err := web.Assets.Open("index.html")
+	if err != nil {
+		return nil, fmt.Errorf("open index: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	index, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+
+	path, err := json.Marshal(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf("<script>window.DASHBOARD_BASE_PATH = %s;</script></head>", path)
+	index = bytes.Replace(index, []byte("</head>"), []byte(script), 1)
+	modified := time.Now()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		http.ServeContent(w, r, "index.html", modified, bytes.NewReader(index))
+	}), nil
+}
+
+// assetHandler serves the embedded web assets under the base path
+func assetHandler(basePath string) http.Handler {
+	return http.StripPrefix(basePath, http.FileServer(web.Assets))
+}
+
+func faviconHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(404)
+}
@@ -0,0 +1,220 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader the header containing the request's ID. A valid ID sent by
+// the client is reused, otherwise a new ID is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen the maximum length of a request ID sent by a client
+const maxRequestIDLen = 64
+
+// ErrorCode a machine-readable error code returned in error responses
+type ErrorCode string
+
+const (
+	// ErrCodeBadRequest the request was malformed
+	ErrCodeBadRequest ErrorCode = "bad_request"
+	// ErrCodeInvalidParameter a query or path parameter was invalid
+	ErrCodeInvalidParameter ErrorCode = "invalid_parameter"
+	// ErrCodeUnauthorized the endpoint requires the API token
+	ErrCodeUnauthorized ErrorCode = "unauthorized"
+	// ErrCodeForbidden the request is not allowed from the client's origin
+	ErrCodeForbidden ErrorCode = "forbidden"
+	// ErrCodeNotFound the requested resource does not exist
+	ErrCodeNotFound ErrorCode = "not_found"
+	// ErrCodeMethodNotAllowed the endpoint does not support the request's
+	// method
+	ErrCodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	// ErrCodeRateLimited the client has exceeded its rate limit
+	ErrCodeRateLimited ErrorCode = "rate_limited"
+	// ErrCodeStorage the dashboard's database or data files could not be
+	// read or written
+	ErrCodeStorage ErrorCode = "storage_error"
+	// ErrCodeInternal an unexpected error occurred
+	ErrCodeInternal ErrorCode = "internal_error"
+)
+
+type (
+	// APIError an error returned by an API handler. The message and code are
+	// sent to the client, the wrapped error is only logged.
+	APIError struct {
+		Code    ErrorCode
+		Message string
+		Err     error
+	}
+
+	// ErrorResponse the response sent for a failed request
+	ErrorResponse struct {
+		APIResponse
+		Code      ErrorCode `json:"code"`
+		RequestID string    `json:"request_id"`
+	}
+)
+
+// Status returns the HTTP status of the error code
+func (c ErrorCode) Status() int {
+	switch c {
+	case ErrCodeBadRequest, ErrCodeInvalidParameter:
+		return http.StatusBadRequest
+	case ErrCodeUnauthorized:
+		return http.StatusUnauthorized
+	case ErrCodeForbidden:
+		return http.StatusForbidden
+	case ErrCodeNotFound:
+		return http.StatusNotFound
+	case ErrCodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case ErrCodeRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.Code, e.Message, e.Err)
+}
+
+// Unwrap returns the wrapped error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// NewError returns an API error with the code and message
+func NewError(code ErrorCode, message string) *APIError {
+	return &APIError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// WrapError returns an API error with the code and message that wraps err
+func WrapError(code ErrorCode, message string, err error) *APIError {
+	return &APIError{
+		Code:    code,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// BadRequest returns an error for a malformed request
+func BadRequest(message string) *APIError {
+	return NewError(ErrCodeBadRequest, message)
+}
+
+// InvalidParameter returns an error for an invalid query or path parameter
+func InvalidParameter(message string) *APIError {
+	return NewError(ErrCodeInvalidParameter, message)
+}
+
+// Unauthorized returns an error for a request without the required API token
+func Unauthorized(message string) *APIError {
+	return NewError(ErrCodeUnauthorized, message)
+}
+
+// Forbidden returns an error for a request that is not allowed
+func Forbidden(message string) *APIError {
+	return NewError(ErrCodeForbidden, message)
+}
+
+// NotFound returns an error for a resource that does not exist
+func NotFound(message string) *APIError {
+	return NewError(ErrCodeNotFound, message)
+}
+
+// StorageError returns an error for a failed database or file operation
+func StorageError(message string, err error) *APIError {
+	return WrapError(ErrCodeStorage, message, err)
+}
+
+// InternalError returns an error for an unexpected failure
+func InternalError(message string, err error) *APIError {
+	return WrapError(ErrCodeInternal, message, err)
+}
+
+// toAPIError converts err to an API error. Errors that are not API errors are
+// treated as internal errors, their message is not sent to the client.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	return InternalError("internal error", err)
+}
+
+// sendError sends the error response for err
+func sendError(err error, w http.ResponseWriter, r *APIRequest) {
+	apiErr := toAPIError(err)
+
+	writeJSON(ErrorResponse{
+		APIResponse: APIResponse{
+			Message: apiErr.Message,
+			Type:    "error",
+		},
+		Code:      apiErr.Code,
+		RequestID: r.ID,
+	}, apiErr.Code.Status(), w, r)
+}
+
+// errorHandler sends the error for requests that do not match an endpoint
+func (router *APIRouter) errorHandler(apiErr *APIError) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestID(r)
+		w.Header().Set(RequestIDHeader, id)
+
+		sendError(apiErr, w, &APIRequest{
+			Request:   r,
+			IPAddress: router.clientIP(r),
+			Timestamp: time.Now(),
+			ID:        id,
+			Log:       router.options.Log.With("request_id", id),
+		})
+	})
+}
+
+// validRequestID returns true if the client supplied request ID is short and
+// only contains characters that are safe to log
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestID returns the request's ID from the request ID header or generates
+// a new one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(buf[:])
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// unixPrefix prefixes listen addresses that are unix socket paths
+const unixPrefix = "unix:"
+
+type listener struct {
+	net.Listener
+	unix bool
+}
+
+// listenUnix listens on the unix socket path, removing a stale socket left by
+// a previous run, and sets the socket's permissions
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = l.Close()
+			return nil, fmt.Errorf("set socket permissions: %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// listen opens a listener for each of the router's listen addresses. Any
+// opened listeners are closed if one fails.
+func (router *APIRouter) listen() (listeners []listener, err error) {
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+
+	for _, address := range router.options.ListenAddresses {
+		var l net.Listener
+		unix := strings.HasPrefix(address, unixPrefix)
+
+		if unix {
+			l, err = listenUnix(strings.TrimPrefix(address, unixPrefix), router.options.SocketMode)
+		} else {
+			l, err = net.Listen("tcp", address)
+		}
+
+		if err != nil {
+			return listeners, fmt.Errorf("listen on %s: %w", address, err)
+		}
+
+		listeners = append(listeners, listener{Listener: l, unix: unix})
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listen addresses")
+	}
+
+	return
+}
+
+// tcpAddress returns the first TCP listen address
+func (router *APIRouter) tcpAddress() string {
+	for _, address := range router.options.ListenAddresses {
+		if !strings.HasPrefix(address, unixPrefix) {
+			return address
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,386 @@
+package router
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/build"
+)
+
+// openAPIVersion the version of the OpenAPI specification the document
+// conforms to
+const openAPIVersion = "3.0.3"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	pathParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+)
+
+type (
+	// QueryParam a query parameter accepted by an endpoint
+	QueryParam struct {
+		Name        string
+		Description string
+		// Type the OpenAPI type of the parameter, defaults to string
+		Type string
+		// Enum the allowed values of the parameter
+		Enum []string
+	}
+
+	// Schema an OpenAPI schema object
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Enum                 []string           `json:"enum,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	}
+
+	openAPIParameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	openAPIMediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	openAPIRequestBody struct {
+		Required bool                        `json:"required"`
+		Content  map[string]openAPIMediaType `json:"content"`
+	}
+
+	openAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]openAPIMediaType `json:"content,omitempty"`
+	}
+
+	openAPIOperation struct {
+		Summary     string                     `json:"summary"`
+		Description string                     `json:"description,omitempty"`
+		OperationID string                     `json:"operationId"`
+		Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+		Responses   map[string]openAPIResponse `json:"responses"`
+	}
+
+	openAPIInfo struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+
+	openAPIServer struct {
+		URL string `json:"url"`
+	}
+
+	openAPIComponents struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	}
+
+	// OpenAPIDocument an OpenAPI 3 document describing the API's endpoints
+	OpenAPIDocument struct {
+		OpenAPI    string                                 `json:"openapi"`
+		Info       openAPIInfo                            `json:"info"`
+		Servers    []openAPIServer                        `json:"servers"`
+		Paths      map[string]map[string]openAPIOperation `json:"paths"`
+		Components openAPIComponents                      `json:"components"`
+	}
+
+	// schemaRegistry generates schemas for Go types, named structs are added
+	// to the document's components and referenced
+	schemaRegistry struct {
+		schemas map[string]*Schema
+		names   map[reflect.Type]string
+	}
+)
+
+// schemaName returns a unique component name for the named type
+func (sr *schemaRegistry) schemaName(t reflect.Type) string {
+	if name, exists := sr.names[t]; exists {
+		return name
+	}
+
+	name := t.Name()
+	if len(name) != 0 {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+
+	// types with the same name from different packages are qualified with
+	// their package's name
+	if _, exists := sr.schemas[name]; exists {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+
+	sr.names[t] = name
+	return name
+}
+
+// schema returns the schema of the type
+func (sr *schemaRegistry) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType), reflect.PtrTo(t).Implements(jsonMarshalerType),
+		t.Implements(textMarshalerType), reflect.PtrTo(t).Implements(textMarshalerType):
+		// custom encodings in the API are string encoded numbers and
+		// identifiers
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "uint64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: sr.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: sr.schema(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return sr.structSchema(t)
+		}
+
+		name := sr.schemaName(t)
+		if _, exists := sr.schemas[name]; !exists {
+			// reserve the name before generating the schema in case the
+			// type references itself
+			sr.schemas[name] = &Schema{}
+			*sr.schemas[name] = *sr.structSchema(t)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema returns an object schema with a property for each encoded
+// field. Embedded structs are flattened the same as encoding/json.
+func (sr *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	sr.addFields(s, t)
+
+	return s
+}
+
+func (sr *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+			sr.addFields(s, ft)
+			continue
+		} else if len(field.PkgPath) != 0 {
+			// unexported
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		s.Properties[name] = sr.schema(field.Type)
+	}
+}
+
+// openAPIPath converts a mux pattern to an OpenAPI path and returns its path
+// parameters
+func openAPIPath(pattern string) (string, []string) {
+	var params []string
+
+	path := pathParamPattern.ReplaceAllStringFunc(pattern, func(match string) string {
+		name := pathParamPattern.FindStringSubmatch(match)[1]
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+
+	return path, params
+}
+
+// operationID returns a camel case identifier from the endpoint's name
+func operationID(name string) string {
+	words := strings.Fields(name)
+
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+func (sr *schemaRegistry) operation(endpoint APIEndpoint, pathParams []string, errSchema *Schema) openAPIOperation {
+	op := openAPIOperation{
+		Summary:     endpoint.Name,
+		Description: endpoint.Description,
+		OperationID: operationID(endpoint.Name),
+		Responses: map[string]openAPIResponse{
+			"default": {
+				Description: "error",
+				Content: map[string]openAPIMediaType{
+					"application/json": {Schema: errSchema},
+				},
+			},
+		},
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	for _, param := range endpoint.Query {
+		paramType := param.Type
+		if len(paramType) == 0 {
+			paramType = "string"
+		}
+
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Schema:      &Schema{Type: paramType, Enum: param.Enum},
+		})
+	}
+
+	if endpoint.Request != nil {
+		op.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content: map[string]openAPIMediaType{
+				"application/json": {Schema: sr.schema(reflect.TypeOf(endpoint.Request))},
+			},
+		}
+	}
+
+	contentTypes := endpoint.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+
+	success := openAPIResponse{Description: "success"}
+	if endpoint.Response != nil {
+		success.Content = make(map[string]openAPIMediaType)
+
+		for _, contentType := range contentTypes {
+			schema := &Schema{Type: "string"}
+			if contentType == "application/json" {
+				schema = sr.schema(reflect.TypeOf(endpoint.Response))
+			}
+
+			success.Content[contentType] = openAPIMediaType{Schema: schema}
+		}
+	}
+	op.Responses["200"] = success
+
+	return op
+}
+
+// OpenAPI generates an OpenAPI document from the router's API endpoints. The
+// server URL is the versioned API prefix.
+func (router *APIRouter) OpenAPI() OpenAPIDocument {
+	sr := &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+
+	doc := OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title:   "Sia Host Dashboard API",
+			Version: build.Version(),
+		},
+		Servers: []openAPIServer{
+			{URL: NormalizeBasePath(router.options.BasePath) + apiPrefix},
+		},
+		Paths: make(map[string]map[string]openAPIOperation),
+	}
+
+	errSchema := sr.schema(reflect.TypeOf(ErrorResponse{}))
+
+	for _, endpoint := range router.endpoints {
+		path, params := openAPIPath(endpoint.Pattern)
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]openAPIOperation)
+		}
+
+		doc.Paths[path][strings.ToLower(endpoint.Method)] = sr.operation(endpoint, params, errSchema)
+	}
+
+	doc.Components.Schemas = sr.schemas
+
+	return doc
+}
+
+// openAPIHandler serves the generated OpenAPI document
+func (router *APIRouter) openAPIHandler() (http.Handler, error) {
+	buf, err := json.MarshalIndent(router.OpenAPI(), "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("encode openapi: %w", err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(buf); err != nil {
+			router.options.Log.Warn("unable to write response", "error", err)
+		}
+	}), nil
+}
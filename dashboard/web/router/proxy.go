@@ -0,0 +1,116 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies parses a list of CIDRs or IP addresses
+func ParseTrustedProxies(values []string) (proxies []*net.IPNet, err error) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", value)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %w", value, err)
+		}
+
+		proxies = append(proxies, ipNet)
+	}
+
+	return
+}
+
+// parseHop parses an address from a forwarding header, removing any port and
+// brackets. Nil is returned for obfuscated or unknown addresses.
+func parseHop(value string) net.IP {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+
+	return net.ParseIP(strings.Trim(value, "[]"))
+}
+
+// forwardedHops returns the client and proxy addresses from the Forwarded
+// header, or the X-Forwarded-For header if it is not set, ordered from the
+// client to the last proxy
+func forwardedHops(r *http.Request) (hops []net.IP) {
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		for _, element := range strings.Split(strings.Join(forwarded, ","), ",") {
+			for _, pair := range strings.Split(element, ";") {
+				parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(parts) != 2 || !strings.EqualFold(parts[0], "for") {
+					continue
+				}
+
+				if ip := parseHop(parts[1]); ip != nil {
+					hops = append(hops, ip)
+				}
+			}
+		}
+
+		return
+	}
+
+	for _, value := range strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",") {
+		if ip := parseHop(value); ip != nil {
+			hops = append(hops, ip)
+		}
+	}
+
+	return
+}
+
+func (router *APIRouter) trustedProxy(ip net.IP) bool {
+	for _, proxy := range router.options.TrustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP returns the address of the client that made the request. The
+// forwarding headers are only used if the request came from a trusted proxy,
+// the first untrusted address from the right is the client. Connections over
+// a unix socket are local and always trusted.
+func (router *APIRouter) clientIP(r *http.Request) string {
+	addresses := forwardedHops(r)
+
+	if remote := parseHop(r.RemoteAddr); remote != nil {
+		addresses = append(addresses, remote)
+	} else if len(addresses) == 0 {
+		return r.RemoteAddr
+	}
+
+	for i := len(addresses) - 1; i >= 0; i-- {
+		if !router.trustedProxy(addresses[i]) {
+			return addresses[i].String()
+		}
+	}
+
+	return addresses[0].String()
+}
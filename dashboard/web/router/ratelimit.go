@@ -1,46 +1,135 @@
 package router
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
 
+// evictInterval how often idle rate limit buckets are removed
+const evictInterval = time.Minute
+
 type (
-	limitter struct {
-		Requests  uint64
-		ResetTime time.Time
+	// tokenBucket allows a burst of up to limit requests, refilling at
+	// limit requests per interval
+	tokenBucket struct {
+		tokens   float64
+		limit    uint64
+		interval time.Duration
+		updated  time.Time
 	}
-)
 
-var (
-	rateUsers = make(map[string]limitter)
-	mu        = sync.Mutex{}
+	rateLimiter struct {
+		mu       sync.Mutex
+		limit    uint64
+		interval time.Duration
+		buckets  map[string]*tokenBucket
+	}
 )
 
-func rateLimitMiddleware(router *APIRouter, endpoint APIEndpoint, handler APIHandlerFunc) APIHandlerFunc {
-	return APIHandlerFunc(func(w http.ResponseWriter, r *APIRequest) {
-		mu.Lock()
+func (b *tokenBucket) rate() float64 {
+	return float64(b.limit) / b.interval.Seconds()
+}
 
-		defer mu.Unlock()
+// refill adds the tokens accumulated since the last update
+func (b *tokenBucket) refill(current time.Time) {
+	b.tokens = math.Min(float64(b.limit), b.tokens+current.Sub(b.updated).Seconds()*b.rate())
+	b.updated = current
+}
+
+// take removes a token from the bucket. If no tokens are available, retry is
+// the time until the next token is available.
+func (b *tokenBucket) take(current time.Time) (allowed bool, retry time.Duration) {
+	b.refill(current)
 
-		limitter := rateUsers[r.IPAddress]
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / b.rate() * float64(time.Second))
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// idle returns true if the bucket would be full, removing it has no effect
+func (b *tokenBucket) idle(current time.Time) bool {
+	return current.Sub(b.updated) >= b.interval
+}
+
+// evict removes idle buckets
+func (rl *rateLimiter) evict() {
+	for range time.Tick(evictInterval) {
 		current := time.Now()
 
-		if limitter.ResetTime.Before(current) {
-			limitter.ResetTime = current.Add(router.options.RateInterval)
-			limitter.Requests = 0
+		rl.mu.Lock()
+		for key, bucket := range rl.buckets {
+			if bucket.idle(current) {
+				delete(rl.buckets, key)
+			}
 		}
+		rl.mu.Unlock()
+	}
+}
+
+// bucket returns the bucket for the request. Endpoints with their own rate
+// limit use a separate bucket from the rest of the endpoints.
+func (rl *rateLimiter) bucket(endpoint APIEndpoint, r *APIRequest, current time.Time) *tokenBucket {
+	key := r.IPAddress
+	limit, interval := rl.limit, rl.interval
 
-		limitter.Requests++
+	if endpoint.RateLimit > 0 && endpoint.RateInterval > 0 {
+		key = endpoint.Name + "|" + key
+		limit, interval = endpoint.RateLimit, endpoint.RateInterval
+	}
 
-		if limitter.Requests > router.options.RateLimit {
-			HandleError("too many requests", 429, w, r)
-			return
+	bucket, exists := rl.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{
+			tokens:   float64(limit),
+			limit:    limit,
+			interval: interval,
+			updated:  current,
 		}
+		rl.buckets[key] = bucket
+	}
 
-		rateUsers[r.IPAddress] = limitter
+	return bucket
+}
+
+// NewRateLimit returns a middleware that limits each IP address to limit
+// requests per interval. Endpoints may declare their own limit.
+func NewRateLimit(limit uint64, interval time.Duration) MiddlewareFunc {
+	rl := &rateLimiter{
+		limit:    limit,
+		interval: interval,
+		buckets:  make(map[string]*tokenBucket),
+	}
 
-		handler(w, r)
-	})
+	go rl.evict()
+
+	return func(router *APIRouter, endpoint APIEndpoint, handler APIHandlerFunc) APIHandlerFunc {
+		return APIHandlerFunc(func(w http.ResponseWriter, r *APIRequest) error {
+			current := time.Now()
+
+			rl.mu.Lock()
+			bucket := rl.bucket(endpoint, r, current)
+			allowed, retry := bucket.take(current)
+			remaining := uint64(bucket.tokens)
+			reset := current.Add(time.Duration((float64(bucket.limit) - bucket.tokens) / bucket.rate() * float64(time.Second)))
+			limit := bucket.limit
+			rl.mu.Unlock()
+
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatUint(limit, 10))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatUint(remaining, 10))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(float64(reset.UnixNano())/float64(time.Second))), 10))
+
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retry.Seconds())), 10))
+				return NewError(ErrCodeRateLimited, "too many requests")
+			}
+
+			return handler(w, r)
+		})
+	}
 }
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// accessLogTimeFormat the timestamp format of the combined log format
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+type (
+	// responseRecorder records the status and size of a response
+	responseRecorder struct {
+		http.ResponseWriter
+		status int
+		bytes  int64
+	}
+
+	// accessLog writes requests to a writer in the combined log format
+	accessLog struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
+)
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w}
+}
+
+// WriteHeader implements http.ResponseWriter
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+
+	n, err := rr.ResponseWriter.Write(p)
+	rr.bytes += int64(n)
+	return n, err
+}
+
+// Status returns the status code of the response. Responses without a body or
+// status are sent as 200 by net/http.
+func (rr *responseRecorder) Status() int {
+	if rr.status == 0 {
+		return http.StatusOK
+	}
+
+	return rr.status
+}
+
+// quoteField quotes a request field for the access log, empty fields are
+// written as "-"
+func quoteField(s string) string {
+	if len(s) == 0 {
+		return `"-"`
+	}
+
+	return strconv.Quote(s)
+}
+
+// write appends the request to the access log in the combined log format
+func (al *accessLog) write(ip string, r *http.Request, rr *responseRecorder, timestamp time.Time) {
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && len(u) != 0 {
+		user = u
+	}
+
+	size := "-"
+	if rr.bytes != 0 {
+		size = strconv.FormatInt(rr.bytes, 10)
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] %s %d %s %s %s\n",
+		ip,
+		user,
+		timestamp.Format(accessLogTimeFormat),
+		strconv.Quote(fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto)),
+		rr.Status(),
+		size,
+		quoteField(r.Referer()),
+		quoteField(r.UserAgent()),
+	)
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	// access logging must not fail the request
+	_, _ = io.WriteString(al.w, line)
+}
+
+// accessLogHandler writes every request served by the handler to the access
+// log
+func (router *APIRouter) accessLogHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp := time.Now()
+		rr := newResponseRecorder(w)
+
+		next.ServeHTTP(rr, r)
+
+		router.accessLog.write(router.clientIP(r), r, rr, timestamp)
+	})
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
-	"net"
 	"net/http"
 	"time"
 
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
-	"github.com/siacentral/sia-host-dashboard/web"
+)
+
+const (
+	// apiPrefix the path prefix of the current version of the API
+	apiPrefix = "/api/v1"
+	// legacyAPIPrefix the unversioned path prefix, an alias of the current
+	// version for existing clients
+	legacyAPIPrefix = "/api"
 )
 
 var (
@@ -22,11 +26,15 @@ var (
 //NewRouter creates a new router
 func NewRouter(endpoints []APIEndpoint, opts APIOptions) (router *APIRouter) {
 	router = &APIRouter{
-		middleware: []MiddlewareFunc{rateLimitMiddleware},
+		middleware: []MiddlewareFunc{NewRateLimit(opts.RateLimit, opts.RateInterval)},
 		endpoints:  endpoints,
 		options:    opts,
 	}
 
+	if opts.AccessLog != nil {
+		router.accessLog = &accessLog{w: opts.AccessLog}
+	}
+
 	return
 }
 
@@ -35,49 +43,160 @@ func (router *APIRouter) AddMiddleware(middleware MiddlewareFunc) {
 	router.middleware = append(router.middleware, middleware)
 }
 
-//ListenAndServe starts the router listening for connections
-func (router *APIRouter) ListenAndServe() error {
+// AddGroup adds a group of endpoints served under the prefix. The group's
+// middleware is used instead of the router's middleware.
+func (router *APIRouter) AddGroup(prefix string, endpoints []APIEndpoint, middleware ...MiddlewareFunc) {
+	router.groups = append(router.groups, endpointGroup{
+		prefix:     prefix,
+		endpoints:  endpoints,
+		middleware: middleware,
+	})
+}
+
+// Handler returns the handler serving the dashboard's assets, its API under
+// the versioned and legacy prefixes, and the router's groups
+func (router *APIRouter) Handler() (http.Handler, error) {
 	var handler http.Handler
 	r := mux.NewRouter().StrictSlash(true)
+	// secure the routes of secure endpoints, CORS is not applied to them
+	secure := make(map[*mux.Route]bool)
+	basePath := NormalizeBasePath(router.options.BasePath)
+	root := r
+
+	if len(basePath) != 0 {
+		root = r.PathPrefix(basePath).Subrouter()
+		r.Path(basePath).Handler(http.RedirectHandler(basePath+"/", http.StatusMovedPermanently))
+	}
+
+	index, err := indexHandler(basePath)
+	if err != nil {
+		return nil, err
+	}
 
-	apiRouter := r.PathPrefix("/api").Subrouter()
+	root.Path("/favicon.ico").HandlerFunc(faviconHandler)
+	root.Path("/").Handler(index)
+	root.Path("/index.html").Handler(index)
 
-	r.Path("/favicon.ico").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(404)
-		_, _ = w.Write([]byte{})
-	})
+	if err := router.mountAPI(root, secure); err != nil {
+		return nil, err
+	}
+
+	for _, group := range router.groups {
+		groupRouter := root.PathPrefix(group.prefix).Subrouter()
 
-	for _, endpoint := range router.endpoints {
-		apiRouter.Methods(endpoint.Method).Path(endpoint.Pattern).
-			Name(endpoint.Name).Handler(router.attachMiddleware(endpoint))
+		for _, endpoint := range group.endpoints {
+			route := groupRouter.Methods(endpoint.Method).Path(endpoint.Pattern).
+				Name(endpoint.Name).Handler(router.attachMiddleware(endpoint, group.middleware))
+			secure[route] = endpoint.Secure
+		}
 	}
 
-	r.PathPrefix("/").Handler(http.FileServer(web.Assets))
+	root.PathPrefix("/").Handler(assetHandler(basePath))
 
 	if router.options.CORS.Enabled {
-		handler = handlers.CORS(handlers.AllowedHeaders(router.options.CORS.Headers),
-			handlers.AllowedOrigins(router.options.CORS.Origins),
-			handlers.AllowedMethods(router.options.CORS.Methods))(r)
+		handler = router.corsHandler(r, secure)
 	} else {
 		handler = r
 	}
 
 	handler = http.TimeoutHandler(handler, time.Minute*1, "timeout")
 
-	l, err := net.Listen("tcp", router.options.ListenAddress)
+	if router.accessLog != nil {
+		handler = router.accessLogHandler(handler)
+	}
+
+	return handler, nil
+}
+
+// mountAPI adds the API's endpoints and OpenAPI document under the versioned
+// and legacy prefixes. The routes of secure endpoints are added to secure.
+func (router *APIRouter) mountAPI(root *mux.Router, secure map[*mux.Route]bool) error {
+	openAPI, err := router.openAPIHandler()
+	if err != nil {
+		return err
+	}
+
+	// the versioned prefix must be matched first, the legacy prefix would
+	// also match its paths
+	for _, prefix := range []string{apiPrefix, legacyAPIPrefix} {
+		apiRouter := root.PathPrefix(prefix).Subrouter()
+		apiRouter.NotFoundHandler = router.errorHandler(NotFound("endpoint not found"))
+		apiRouter.MethodNotAllowedHandler = router.errorHandler(NewError(ErrCodeMethodNotAllowed, "method not allowed"))
+		apiRouter.Methods("GET").Path("/openapi.json").Handler(openAPI)
+
+		for _, endpoint := range router.endpoints {
+			route := apiRouter.Methods(endpoint.Method).Path(endpoint.Pattern)
+			// the routes share a name map, only the versioned route is
+			// named so URLs built from the name use the current version
+			if prefix == apiPrefix {
+				route = route.Name(endpoint.Name)
+			}
+
+			route.Handler(router.attachMiddleware(endpoint, router.middleware))
+			secure[route] = endpoint.Secure
+		}
+	}
+
+	return nil
+}
+
+//ListenAndServe starts the router listening for connections
+func (router *APIRouter) ListenAndServe() error {
+	handler, err := router.Handler()
+	if err != nil {
+		return err
+	}
+
+	listeners, err := router.listen()
 	if err != nil {
 		return err
 	}
 
 	router.server = &http.Server{
 		Handler:      handler,
-		Addr:         router.options.ListenAddress,
 		WriteTimeout: 60 * time.Second,
 		ReadTimeout:  60 * time.Second,
 	}
 
-	if err := router.server.Serve(l); err != nil && errors.Is(err, http.ErrServerClosed) {
-		return err
+	tlsOpts := router.options.TLS
+	tlsEnabled := len(tlsOpts.CertFile) != 0 && len(tlsOpts.KeyFile) != 0
+
+	if tlsEnabled && len(tlsOpts.RedirectAddress) != 0 {
+		router.redirect = &http.Server{
+			Handler:      redirectHandler(router.tcpAddress()),
+			Addr:         tlsOpts.RedirectAddress,
+			WriteTimeout: 60 * time.Second,
+			ReadTimeout:  60 * time.Second,
+		}
+
+		go func() {
+			if err := router.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				router.options.Log.Error("redirect listener failed", "error", err)
+			}
+		}()
+	}
+
+	errCh := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l listener) {
+			// unix sockets are only reachable locally, TLS is only used
+			// for TCP listeners
+			if tlsEnabled && !l.unix {
+				errCh <- router.server.ServeTLS(l, tlsOpts.CertFile, tlsOpts.KeyFile)
+			} else {
+				errCh <- router.server.Serve(l)
+			}
+		}(l)
+	}
+
+	for range listeners {
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// stop the remaining listeners
+			if closeErr := router.server.Close(); closeErr != nil {
+				router.options.Log.Warn("unable to close listeners", "error", closeErr)
+			}
+			return err
+		}
 	}
 
 	return nil
@@ -89,6 +208,12 @@ func (router *APIRouter) Shutdown(ctx context.Context) error {
 		return ErrNotRunning
 	}
 
+	if router.redirect != nil {
+		if err := router.redirect.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
 	return router.server.Shutdown(ctx)
 }
 
@@ -100,68 +225,95 @@ func chainMiddleware(router *APIRouter, endpoint APIEndpoint, middleware ...Midd
 	return middleware[0](router, endpoint, chainMiddleware(router, endpoint, middleware[1:]...))
 }
 
-func (router *APIRouter) attachMiddleware(endpoint APIEndpoint) http.Handler {
+func (router *APIRouter) attachMiddleware(endpoint APIEndpoint, middleware []MiddlewareFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var err error
-		var remoteAddr string
-
-		if forwardHeader := r.Header.Get("X-Forwarded-For"); len(forwardHeader) > 0 {
-			remoteAddr = forwardHeader
-		} else {
-			remoteAddr = r.RemoteAddr
-		}
 
+		rr := newResponseRecorder(w)
+		ip := router.clientIP(r)
+		id := requestID(r)
 		request := &APIRequest{
 			Request:   r,
-			IPAddress: remoteAddr,
+			IPAddress: ip,
 			Timestamp: time.Now(),
+			ID:        id,
+			Log:       router.options.Log.With("request_id", id, "endpoint", endpoint.Name, "ip", ip),
 		}
 
-		defer func() { logRequest(endpoint, request, err) }()
+		rr.Header().Set(RequestIDHeader, id)
+
+		defer func() {
+			latency := time.Since(request.Timestamp)
+
+			router.stats.record(endpoint, rr.Status(), rr.bytes, latency)
+			logRequest(endpoint, request, rr, latency, err)
+		}()
 
-		chainMiddleware(router, endpoint, append(router.middleware, endpoint.Middleware...)...)(w, request)
+		chain := append(append([]MiddlewareFunc{}, middleware...), requireSecure)
+		chain = append(chain, endpoint.Middleware...)
+		err = chainMiddleware(router, endpoint, chain...)(rr, request)
+
+		if err != nil {
+			sendError(err, rr, request)
+		}
 	})
 }
 
-func logRequest(endpoint APIEndpoint, request *APIRequest, err error) {
+// logRequest logs the request at debug level, server errors are logged as
+// errors
+func logRequest(endpoint APIEndpoint, request *APIRequest, rr *responseRecorder, latency time.Duration, err error) {
+	fields := []interface{}{
+		"method", endpoint.Method,
+		"path", request.Request.URL.Path,
+		"status", rr.Status(),
+		"bytes", rr.bytes,
+		"duration", latency,
+	}
+
 	if err != nil {
-		log.Println(err)
+		fields = append(fields, "code", toAPIError(err).Code, "error", err)
+	}
+
+	if rr.Status() >= 500 {
+		request.Log.Error("request failed", fields...)
+		return
 	}
 
-	log.Printf(
-		"%s %s %s %s %s",
-		request.IPAddress,
-		endpoint.Name,
-		endpoint.Method,
-		request.Request.URL.Path,
-		time.Since(request.Timestamp),
-	)
+	request.Log.Debug("request", fields...)
 }
 
-//HandleError returns an error response with the specified message and http status
-func HandleError(message string, status int, w http.ResponseWriter, r *APIRequest) {
-	var resp APIResponse
+// SendJSONResponse encodes the response as JSON and sends it with the
+// status. An error is returned if the response cannot be encoded, nothing has
+// been written yet so the handler can return it.
+func SendJSONResponse(response interface{}, status int, w http.ResponseWriter, r *APIRequest) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return InternalError("unable to encode response", err)
+	}
 
-	resp.Message = message
-	resp.Type = "error"
+	writeData(data, status, w, r)
 
-	SendJSONResponse(resp, status, w, r)
+	return nil
 }
 
-//SendJSONResponse SendJSONResponse
-func SendJSONResponse(response interface{}, status int, w http.ResponseWriter, r *APIRequest) {
+// writeJSON sends the response, failing to encode it sends a plain internal
+// error instead
+func writeJSON(response interface{}, status int, w http.ResponseWriter, r *APIRequest) {
 	data, err := json.Marshal(response)
-
 	if err != nil {
-		w.WriteHeader(500)
-		log.Println("Send Response:", err)
+		r.Log.Error("unable to encode response", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
 	}
 
+	writeData(data, status, w, r)
+}
+
+func writeData(data []byte, status int, w http.ResponseWriter, r *APIRequest) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_, err = w.Write(data)
 
-	if err != nil {
-		log.Println("Write data:", err)
+	if _, err := w.Write(data); err != nil {
+		r.Log.Warn("unable to write response", "error", err)
 	}
 }
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/handlers"
+	"github.com/gorilla/mux"
+)
+
+// validToken returns true if the request has the router's API token as a
+// bearer token or as the password of basic auth
+func (router *APIRouter) validToken(r *http.Request) bool {
+	token := router.options.AuthToken
+	if len(token) == 0 {
+		return false
+	}
+
+	value := r.Header.Get("Authorization")
+	if _, password, ok := r.BasicAuth(); ok {
+		value = password
+	} else if strings.HasPrefix(value, "Bearer ") {
+		value = strings.TrimPrefix(value, "Bearer ")
+	} else {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1
+}
+
+// requestHost returns the host the client requested. The forwarded host is
+// only used if the request came from a trusted proxy.
+func (router *APIRouter) requestHost(r *http.Request) string {
+	if host := r.Header.Get("X-Forwarded-Host"); len(host) != 0 {
+		if remote := parseHop(r.RemoteAddr); remote != nil && router.trustedProxy(remote) {
+			return strings.TrimSpace(strings.Split(host, ",")[0])
+		}
+	}
+
+	return r.Host
+}
+
+// requestOrigin returns true if the request was sent by a browser from the
+// dashboard's own origin and false if it was sent from another origin. known
+// is false if the request does not identify its origin, the request was not
+// sent by a browser.
+func (router *APIRouter) requestOrigin(r *http.Request) (sameOrigin, known bool) {
+	if origin := r.Header.Get("Origin"); len(origin) != 0 {
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false, true
+		}
+
+		return strings.EqualFold(u.Host, router.requestHost(r)), true
+	}
+
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "":
+		return false, false
+	case "same-origin", "none":
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+// localClient returns true if the request was sent over a unix socket or
+// from a loopback address. Forwarded requests are only local if every
+// forwarded address is a loopback address.
+func localClient(r *http.Request) bool {
+	// unix socket connections do not have a remote IP address
+	if remote := parseHop(r.RemoteAddr); remote != nil && !remote.IsLoopback() {
+		return false
+	}
+
+	for _, hop := range forwardedHops(r) {
+		if !hop.IsLoopback() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkSecure returns an error if the request to a secure endpoint is not
+// allowed. Requests with the API token are always allowed. If a token is
+// set, browsers must send the request from the dashboard's own origin and
+// other clients must send the token. Without a token, only local clients
+// are allowed.
+func (router *APIRouter) checkSecure(r *http.Request) error {
+	if router.validToken(r) {
+		return nil
+	}
+
+	hasToken := len(router.options.AuthToken) != 0
+	sameOrigin, known := router.requestOrigin(r)
+	switch {
+	case known && !sameOrigin:
+		return Forbidden("cross-origin requests are not allowed")
+	case hasToken && known:
+		return nil
+	case hasToken:
+		return Unauthorized("missing or invalid API token")
+	case !localClient(r):
+		return Forbidden("only local clients are allowed without an API token")
+	}
+
+	return nil
+}
+
+// requireSecure checks requests to secure endpoints. It runs after the
+// router's rate limit so failed token checks are limited.
+func requireSecure(router *APIRouter, endpoint APIEndpoint, handler APIHandlerFunc) APIHandlerFunc {
+	if !endpoint.Secure {
+		return handler
+	}
+
+	return APIHandlerFunc(func(w http.ResponseWriter, r *APIRequest) error {
+		if err := router.checkSecure(r.Request); err != nil {
+			return err
+		}
+
+		return handler(w, r)
+	})
+}
+
+// secureRoute returns true if the request, or the request a CORS preflight
+// is checking, matches a secure endpoint
+func secureRoute(r *mux.Router, secure map[*mux.Route]bool, req *http.Request) bool {
+	if method := req.Header.Get("Access-Control-Request-Method"); req.Method == http.MethodOptions && len(method) != 0 {
+		req = req.Clone(req.Context())
+		req.Method = method
+	}
+
+	var match mux.RouteMatch
+	return r.Match(req, &match) && secure[match.Route]
+}
+
+// corsHandler applies the router's CORS policy to every route except secure
+// endpoints. Secure endpoints are only available to the dashboard's own
+// origin.
+func (router *APIRouter) corsHandler(r *mux.Router, secure map[*mux.Route]bool) http.Handler {
+	opts := router.options.CORS
+	cors := handlers.CORS(handlers.AllowedHeaders(opts.Headers),
+		handlers.AllowedOrigins(opts.Origins),
+		handlers.AllowedMethods(opts.Methods))(r)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if secureRoute(r, secure, req) {
+			r.ServeHTTP(w, req)
+			return
+		}
+
+		cors.ServeHTTP(w, req)
+	})
+}
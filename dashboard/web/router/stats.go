@@ -0,0 +1,155 @@
+package router
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets the upper bounds of the latency histogram buckets. Requests
+// slower than the last bound are counted in an extra unbounded bucket.
+var latencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+type (
+	// LatencyBucket the number of requests that completed within the bucket's
+	// bound and above the previous bucket's bound. The last bucket has no
+	// upper bound.
+	LatencyBucket struct {
+		UpperBound float64 `json:"le_ms,omitempty"`
+		Count      uint64  `json:"count"`
+	}
+
+	// EndpointStats usage metrics for an API endpoint since the router
+	// started
+	EndpointStats struct {
+		Name         string          `json:"name"`
+		Method       string          `json:"method"`
+		Pattern      string          `json:"pattern"`
+		Requests     uint64          `json:"requests"`
+		ClientErrors uint64          `json:"client_errors"`
+		ServerErrors uint64          `json:"server_errors"`
+		BytesWritten uint64          `json:"bytes_written"`
+		MeanLatency  float64         `json:"mean_latency_ms"`
+		MaxLatency   float64         `json:"max_latency_ms"`
+		Latency      []LatencyBucket `json:"latency"`
+	}
+
+	endpointMetrics struct {
+		endpoint     APIEndpoint
+		requests     uint64
+		clientErrors uint64
+		serverErrors uint64
+		bytes        uint64
+		total        time.Duration
+		max          time.Duration
+		buckets      []uint64
+	}
+
+	apiStats struct {
+		mu        sync.Mutex
+		endpoints map[string]*endpointMetrics
+	}
+)
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// record adds a completed request to the endpoint's metrics
+func (s *apiStats) record(endpoint APIEndpoint, status int, bytes int64, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.endpoints == nil {
+		s.endpoints = make(map[string]*endpointMetrics)
+	}
+
+	m, exists := s.endpoints[endpoint.Name]
+	if !exists {
+		m = &endpointMetrics{
+			endpoint: endpoint,
+			buckets:  make([]uint64, len(latencyBuckets)+1),
+		}
+		s.endpoints[endpoint.Name] = m
+	}
+
+	m.requests++
+	m.bytes += uint64(bytes)
+	m.total += latency
+
+	if latency > m.max {
+		m.max = latency
+	}
+
+	if status >= 500 {
+		m.serverErrors++
+	} else if status >= 400 {
+		m.clientErrors++
+	}
+
+	i := sort.Search(len(latencyBuckets), func(i int) bool {
+		return latency <= latencyBuckets[i]
+	})
+	m.buckets[i]++
+}
+
+// snapshot returns the metrics of every endpoint that has served a request,
+// sorted by name
+func (s *apiStats) snapshot() (stats []EndpointStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.endpoints {
+		es := EndpointStats{
+			Name:         m.endpoint.Name,
+			Method:       m.endpoint.Method,
+			Pattern:      m.endpoint.Pattern,
+			Requests:     m.requests,
+			ClientErrors: m.clientErrors,
+			ServerErrors: m.serverErrors,
+			BytesWritten: m.bytes,
+			MaxLatency:   milliseconds(m.max),
+		}
+
+		if m.requests != 0 {
+			es.MeanLatency = milliseconds(m.total / time.Duration(m.requests))
+		}
+
+		for i, count := range m.buckets {
+			var bound float64
+			if i < len(latencyBuckets) {
+				bound = milliseconds(latencyBuckets[i])
+			}
+
+			es.Latency = append(es.Latency, LatencyBucket{
+				UpperBound: bound,
+				Count:      count,
+			})
+		}
+
+		stats = append(stats, es)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return
+}
+
+// Stats returns the usage metrics of the router's endpoints
+func (router *APIRouter) Stats() []EndpointStats {
+	return router.stats.snapshot()
+}
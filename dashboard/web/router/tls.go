@@ -0,0 +1,149 @@
+package router
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// selfSignedValidity how long a generated certificate is valid for
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// certificateValid returns true if the certificate and key can be loaded and
+// the certificate has not expired
+func certificateValid(certFile, keyFile string) bool {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil || len(pair.Certificate) == 0 {
+		return false
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+
+	return time.Now().Before(cert.NotAfter)
+}
+
+// certificateHosts returns the names and addresses the generated certificate
+// is valid for: localhost, the machine's hostname, and its interface
+// addresses
+func certificateHosts() (names []string, ips []net.IP) {
+	names = append(names, "localhost")
+
+	if hostname, err := os.Hostname(); err == nil {
+		names = append(names, hostname)
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return names, []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+
+	return
+}
+
+func writePEM(path, blockType string, buf []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	if err := pem.Encode(f, &pem.Block{Type: blockType, Bytes: buf}); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// EnsureSelfSignedCertificate generates a self-signed certificate and key if
+// the files do not exist or the certificate has expired
+func EnsureSelfSignedCertificate(certFile, keyFile string) error {
+	if certificateValid(certFile, keyFile) {
+		return nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("generate serial: %w", err)
+	}
+
+	names, ips := certificateHosts()
+	current := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"Sia Host Dashboard"},
+		},
+		NotBefore:             current.Add(-time.Hour),
+		NotAfter:              current.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              names,
+		IPAddresses:           ips,
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("create certificate: %w", err)
+	}
+
+	keyBuf, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+
+	if err := writePEM(keyFile, "EC PRIVATE KEY", keyBuf, 0600); err != nil {
+		return fmt.Errorf("write key: %w", err)
+	} else if err := writePEM(certFile, "CERTIFICATE", cert, 0644); err != nil {
+		return fmt.Errorf("write certificate: %w", err)
+	}
+
+	return nil
+}
+
+// redirectHandler redirects every request to the same path over HTTPS on the
+// port the API is listening on
+func redirectHandler(listenAddress string) http.Handler {
+	_, port, _ := net.SplitHostPort(listenAddress)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+
+		if len(port) != 0 && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		target := *r.URL
+		target.Scheme = "https"
+		target.Host = host
+
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+	})
+}
@@ -1,17 +1,50 @@
 package router
 
 import (
+	"io"
+	"net"
 	"net/http"
+	"os"
 	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/logging"
 )
 
 type (
 	//APIOptions options when initializing the API
 	APIOptions struct {
-		ListenAddress string        `json:"listen_address"`
-		CORS          CORSOptions   `json:"cors"`
-		RateInterval  time.Duration `json:"rate_interval"`
-		RateLimit     uint64        `json:"rate_limit"`
+		// ListenAddresses the TCP addresses or unix socket paths, prefixed
+		// with "unix:", to listen on
+		ListenAddresses []string    `json:"listen_addresses"`
+		SocketMode      os.FileMode `json:"socket_mode"`
+		// BasePath the path prefix the dashboard is served under
+		BasePath     string        `json:"base_path"`
+		CORS         CORSOptions   `json:"cors"`
+		RateInterval time.Duration `json:"rate_interval"`
+		RateLimit    uint64        `json:"rate_limit"`
+		TLS          TLSOptions    `json:"tls"`
+		// AuthToken if set, requests to secure endpoints from outside the
+		// dashboard's origin must send it as a bearer token or basic auth
+		// password
+		AuthToken string `json:"-"`
+		// TrustedProxies forwarding headers are only used for requests
+		// from these networks
+		TrustedProxies []*net.IPNet `json:"-"`
+		// Log the logger for requests and server errors
+		Log *logging.Logger `json:"-"`
+		// AccessLog if set, every request is written to it in the combined
+		// log format
+		AccessLog io.Writer `json:"-"`
+	}
+
+	// TLSOptions TLS options for the API. TLS is enabled if the certificate
+	// and key files are set.
+	TLSOptions struct {
+		CertFile string `json:"cert_file"`
+		KeyFile  string `json:"key_file"`
+		// RedirectAddress if set, a plain HTTP listener on the address
+		// redirects requests to HTTPS
+		RedirectAddress string `json:"redirect_address"`
 	}
 
 	//CORSOptions cors options for the API
@@ -30,13 +63,19 @@ type (
 		AuthToken    string
 		IPAddress    string
 		Timestamp    time.Time
+		// ID identifies the request in logs and error responses
+		ID string
+		// Log the router's logger with the request's fields attached
+		Log *logging.Logger
 	}
 
 	//MiddlewareFunc a middleware function for the router
 	MiddlewareFunc func(*APIRouter, APIEndpoint, APIHandlerFunc) APIHandlerFunc
 
-	//APIHandlerFunc a handler for an API endpoint
-	APIHandlerFunc func(http.ResponseWriter, *APIRequest)
+	// APIHandlerFunc a handler for an API endpoint. Handlers that fail return
+	// an error instead of writing a response, the router sends the error
+	// response.
+	APIHandlerFunc func(http.ResponseWriter, *APIRequest) error
 
 	//APIEndpoint an endpoint of the API to retrieve or set data
 	APIEndpoint struct {
@@ -47,6 +86,23 @@ type (
 		Permissions []string
 		Middleware  []MiddlewareFunc
 		Handler     APIHandlerFunc
+		// RateLimit and RateInterval override the router's rate limit for
+		// the endpoint if both are set
+		RateLimit    uint64
+		RateInterval time.Duration
+		// Description documents the endpoint in the OpenAPI document
+		Description string
+		// Query the query parameters accepted by the endpoint
+		Query []QueryParam
+		// Request an instance of the request body's type, nil if the
+		// endpoint does not accept a body
+		Request interface{}
+		// Response an instance of the successful response's type
+		Response interface{}
+		// ContentTypes the content types of the successful response,
+		// defaults to application/json. Other types are documented as
+		// strings.
+		ContentTypes []string
 	}
 
 	//APIResponse APIResponse
@@ -63,11 +119,23 @@ type (
 		ExpirationDate time.Time `json:"expiration_date"`
 	}
 
+	// endpointGroup endpoints served under a prefix with their own
+	// middleware
+	endpointGroup struct {
+		prefix     string
+		endpoints  []APIEndpoint
+		middleware []MiddlewareFunc
+	}
+
 	//APIRouter an API router
 	APIRouter struct {
 		options    APIOptions
 		middleware []MiddlewareFunc
 		endpoints  []APIEndpoint
+		groups     []endpointGroup
 		server     *http.Server
+		redirect   *http.Server
+		stats      apiStats
+		accessLog  *accessLog
 	}
 )
@@ -0,0 +1,267 @@
+package web
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+const totalsFieldPrefix = "totals."
+
+type (
+	// ShareRequest the fields to create a share token for
+	ShareRequest struct {
+		Fields []string `json:"fields"`
+	}
+
+	// ShareResponse a created share token
+	ShareResponse struct {
+		router.APIResponse
+		Share types.ShareToken `json:"share"`
+	}
+
+	// SharesResponse the share tokens and the fields that can be shared
+	SharesResponse struct {
+		router.APIResponse
+		Fields []string           `json:"fields"`
+		Shares []types.ShareToken `json:"shares"`
+	}
+
+	// SharedStatsResponse the stats shared by a token
+	SharedStatsResponse struct {
+		router.APIResponse
+		Stats map[string]json.RawMessage `json:"stats"`
+	}
+)
+
+var (
+	// shareableFields the fields from the host's status and totals that can
+	// be shared publicly. Wallet state and collateral are never shared.
+	shareableFields = map[string]bool{
+		"online":               true,
+		"accepting_contracts":  true,
+		"version":              true,
+		"active_contracts":     true,
+		"successful_contracts": true,
+		"failed_contracts":     true,
+		"used_storage":         true,
+		"total_storage":        true,
+		"storage_delta":        true,
+		"upload_bandwidth":     true,
+		"download_bandwidth":   true,
+		"earned_revenue":       true,
+		"potential_revenue":    true,
+		"host_settings":        true,
+		"first_seen":           true,
+		"start_time":           true,
+		"totals.day":           true,
+		"totals.month":         true,
+		"totals.year":          true,
+		"totals.total":         true,
+	}
+
+	sharePage = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Host Stats</title>
+</head>
+<body style="font-family: sans-serif;">
+	<h1>Host Stats</h1>
+	<table>
+		{{ range $field, $value := . }}<tr><td>{{ $field }}</td><td><pre>{{ printf "%s" $value }}</pre></td></tr>
+		{{ end }}
+	</table>
+</body>
+</html>
+`))
+)
+
+// jsonFields returns the top level fields of the value's JSON encoding
+func jsonFields(v interface{}) (fields map[string]json.RawMessage, err error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(buf, &fields)
+	return
+}
+
+// sharedStats returns the values of the shared fields
+func sharedStats(share types.ShareToken) (map[string]json.RawMessage, error) {
+	status, err := getHostStatus()
+	if err != nil {
+		return nil, fmt.Errorf("get status: %w", err)
+	}
+
+	statusFields, err := jsonFields(status)
+	if err != nil {
+		return nil, fmt.Errorf("encode status: %w", err)
+	}
+
+	var totalFields map[string]json.RawMessage
+	stats := make(map[string]json.RawMessage)
+
+	for _, field := range share.Fields {
+		if !shareableFields[field] {
+			continue
+		}
+
+		if !strings.HasPrefix(field, totalsFieldPrefix) {
+			stats[field] = statusFields[field]
+			continue
+		}
+
+		if totalFields == nil {
+			totals, err := GetHostTotals(time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("get totals: %w", err)
+			}
+
+			if totalFields, err = jsonFields(totals); err != nil {
+				return nil, fmt.Errorf("encode totals: %w", err)
+			}
+		}
+
+		stats[field] = totalFields[strings.TrimPrefix(field, totalsFieldPrefix)]
+	}
+
+	return stats, nil
+}
+
+func generateShareToken() (string, error) {
+	buf := make([]byte, 16)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func handleGetShares(w http.ResponseWriter, r *router.APIRequest) error {
+	shares, err := persist.GetShareTokens()
+	if err != nil {
+		return router.StorageError("unable to retrieve shares", err)
+	}
+
+	resp := SharesResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully retrieved shares",
+			Type:    "success",
+		},
+		Shares: shares,
+	}
+
+	for field := range shareableFields {
+		resp.Fields = append(resp.Fields, field)
+	}
+
+	sort.Strings(resp.Fields)
+
+	return router.SendJSONResponse(resp, 200, w, r)
+}
+
+func handleCreateShare(w http.ResponseWriter, r *router.APIRequest) error {
+	var req ShareRequest
+
+	if err := json.NewDecoder(r.Request.Body).Decode(&req); err != nil {
+		return router.BadRequest("unable to decode request")
+	}
+
+	if len(req.Fields) == 0 {
+		return router.InvalidParameter("at least one field must be shared")
+	}
+
+	for _, field := range req.Fields {
+		if !shareableFields[field] {
+			return router.InvalidParameter(fmt.Sprintf("field %q cannot be shared", field))
+		}
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return router.InternalError("unable to create share", err)
+	}
+
+	share := types.ShareToken{
+		Token:   token,
+		Fields:  req.Fields,
+		Created: time.Now().UTC(),
+	}
+
+	if err := persist.SaveShareToken(share); err != nil {
+		return router.StorageError("unable to create share", err)
+	}
+
+	return router.SendJSONResponse(ShareResponse{
+		APIResponse: router.APIResponse{
+			Message: "successfully created share",
+			Type:    "success",
+		},
+		Share: share,
+	}, 200, w, r)
+}
+
+func handleRevokeShare(w http.ResponseWriter, r *router.APIRequest) error {
+	exists, err := persist.DeleteShareToken(mux.Vars(r.Request)["token"])
+	if err != nil {
+		return router.StorageError("unable to revoke share", err)
+	} else if !exists {
+		return router.NotFound("share not found")
+	}
+
+	return router.SendJSONResponse(router.APIResponse{
+		Message: "successfully revoked share",
+		Type:    "success",
+	}, 200, w, r)
+}
+
+func handleGetSharedStats(w http.ResponseWriter, r *router.APIRequest) error {
+	share, exists, err := persist.GetShareToken(mux.Vars(r.Request)["token"])
+	if err != nil {
+		return router.StorageError("unable to retrieve share", err)
+	} else if !exists {
+		return router.NotFound("share not found")
+	}
+
+	stats, err := sharedStats(share)
+	if err != nil {
+		return router.StorageError("unable to retrieve stats", err)
+	}
+
+	if r.Request.URL.Query().Get("format") == "json" {
+		return router.SendJSONResponse(SharedStatsResponse{
+			APIResponse: router.APIResponse{
+				Message: "successfully retrieved stats",
+				Type:    "success",
+			},
+			Stats: stats,
+		}, 200, w, r)
+	}
+
+	var buf bytes.Buffer
+	if err := sharePage.Execute(&buf, stats); err != nil {
+		return router.InternalError("unable to render stats", err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		r.Log.Warn("unable to write response", "error", err)
+	}
+
+	return nil
+}
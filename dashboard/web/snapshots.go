@@ -1,7 +1,7 @@
 package web
 
 import (
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,19 +12,22 @@ import (
 )
 
 type (
-	hostResponse struct {
+	// HostResponse the time range of a response's data
+	HostResponse struct {
 		router.APIResponse
 		Start time.Time `json:"start"`
 		End   time.Time `json:"end"`
 	}
 
-	hostSnapshotResponse struct {
-		hostResponse
+	// HostSnapshotResponse the host's daily snapshots
+	HostSnapshotResponse struct {
+		HostResponse
 		Snapshots []types.HostSnapshot `json:"snapshots"`
 	}
 
-	hostTotalResponse struct {
-		hostResponse
+	// HostTotalResponse the host's totals for the day, month, year, and lifetime
+	HostTotalResponse struct {
+		HostResponse
 		Day   types.HostSnapshot `json:"day"`
 		Month types.HostSnapshot `json:"month"`
 		Year  types.HostSnapshot `json:"year"`
@@ -52,7 +55,7 @@ func parseTimeParams(r *router.APIRequest, params ...string) (timestamps []time.
 	return
 }
 
-func handleGetHostSnapshots(w http.ResponseWriter, r *router.APIRequest) {
+func handleGetHostSnapshots(w http.ResponseWriter, r *router.APIRequest) error {
 	date := parseTimeParams(r, "end")[0]
 	if date.IsZero() {
 		date = time.Now().UTC()
@@ -64,8 +67,7 @@ func handleGetHostSnapshots(w http.ResponseWriter, r *router.APIRequest) {
 	end := start.AddDate(1, 4, -1)
 
 	if end.Before(start) {
-		router.HandleError("end must be after start", 400, w, r)
-		return
+		return router.InvalidParameter("end must be after start")
 	}
 
 	start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, time.UTC)
@@ -74,12 +76,11 @@ func handleGetHostSnapshots(w http.ResponseWriter, r *router.APIRequest) {
 	snapshots, err := persist.GetDailySnapshots(start, end)
 
 	if err != nil {
-		router.HandleError("unable to get snapshots", 400, w, r)
-		return
+		return router.StorageError("unable to retrieve snapshots", err)
 	}
 
-	router.SendJSONResponse(hostSnapshotResponse{
-		hostResponse: hostResponse{
+	return router.SendJSONResponse(HostSnapshotResponse{
+		HostResponse: HostResponse{
 			APIResponse: router.APIResponse{
 				Message: "successfully retrieved snapshots",
 				Type:    "success",
@@ -91,9 +92,9 @@ func handleGetHostSnapshots(w http.ResponseWriter, r *router.APIRequest) {
 	}, 200, w, r)
 }
 
-func buildTotalResponse(start, end, date time.Time, lastMetadata types.HostMeta) hostTotalResponse {
-	return hostTotalResponse{
-		hostResponse: hostResponse{
+func buildTotalResponse(start, end, date time.Time, lastMetadata types.HostMeta) HostTotalResponse {
+	return HostTotalResponse{
+		HostResponse: HostResponse{
 			APIResponse: router.APIResponse{
 				Message: "successfully retrieved totals",
 				Type:    "success",
@@ -118,39 +119,34 @@ func buildTotalResponse(start, end, date time.Time, lastMetadata types.HostMeta)
 			EarnedRevenue:       lastMetadata.EarnedRevenue,
 			PotentialRevenue:    lastMetadata.PotentialRevenue,
 			BurntCollateral:     lastMetadata.BurntCollateral,
+			EarnedBreakdown:     lastMetadata.EarnedBreakdown,
+			PotentialBreakdown:  lastMetadata.PotentialBreakdown,
 			Timestamp:           lastMetadata.Timestamp,
 		},
 	}
 }
 
-func handleGetHostTotals(w http.ResponseWriter, r *router.APIRequest) {
+// GetHostTotals returns the host's totals for the day, month, and year of the
+// date
+func GetHostTotals(date time.Time) (resp HostTotalResponse, err error) {
 	var start, end time.Time
 
 	current := time.Now()
-	date := parseTimeParams(r, "date")[0]
-	if date.IsZero() {
-		date = current
-	}
-
 	date = date.Truncate(time.Hour).UTC()
 	start = time.Date(date.Year(), 1, 1, date.Hour(), 0, 0, 0, time.UTC)
 	end = start.AddDate(1, 0, 0)
 
 	dailySnapshots, err := persist.GetDailySnapshots(start, end)
 	if err != nil {
-		router.HandleError("unable to retrieve snapshots", 400, w, r)
-		log.Println(err)
-		return
+		return resp, fmt.Errorf("get snapshots: %w", err)
 	}
 
 	lastMetadata, err := persist.GetLastMetadata()
 	if err != nil {
-		router.HandleError("unable to retrieve metadata", 400, w, r)
-		log.Println(err)
-		return
+		return resp, fmt.Errorf("get metadata: %w", err)
 	}
 
-	resp := buildTotalResponse(start, end, date, lastMetadata)
+	resp = buildTotalResponse(start, end, date, lastMetadata)
 	dy, dm, _ := date.Date()
 	for _, snapshot := range dailySnapshots {
 		sy, sm, _ := snapshot.Timestamp.Date()
@@ -180,6 +176,8 @@ func handleGetHostTotals(w http.ResponseWriter, r *router.APIRequest) {
 			resp.Month.EarnedRevenue = resp.Month.EarnedRevenue.Add(snapshot.EarnedRevenue)
 			resp.Month.PotentialRevenue = resp.Month.PotentialRevenue.Add(snapshot.PotentialRevenue)
 			resp.Month.BurntCollateral = resp.Month.BurntCollateral.Add(snapshot.BurntCollateral)
+			resp.Month.EarnedBreakdown = resp.Month.EarnedBreakdown.Add(snapshot.EarnedBreakdown)
+			resp.Month.PotentialBreakdown = resp.Month.PotentialBreakdown.Add(snapshot.PotentialBreakdown)
 		}
 
 		if sy == dy {
@@ -191,8 +189,24 @@ func handleGetHostTotals(w http.ResponseWriter, r *router.APIRequest) {
 			resp.Year.EarnedRevenue = resp.Year.EarnedRevenue.Add(snapshot.EarnedRevenue)
 			resp.Year.PotentialRevenue = resp.Year.PotentialRevenue.Add(snapshot.PotentialRevenue)
 			resp.Year.BurntCollateral = resp.Year.BurntCollateral.Add(snapshot.BurntCollateral)
+			resp.Year.EarnedBreakdown = resp.Year.EarnedBreakdown.Add(snapshot.EarnedBreakdown)
+			resp.Year.PotentialBreakdown = resp.Year.PotentialBreakdown.Add(snapshot.PotentialBreakdown)
 		}
 	}
 
-	router.SendJSONResponse(resp, 200, w, r)
+	return resp, nil
+}
+
+func handleGetHostTotals(w http.ResponseWriter, r *router.APIRequest) error {
+	date := parseTimeParams(r, "date")[0]
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	resp, err := GetHostTotals(date)
+	if err != nil {
+		return router.StorageError("unable to retrieve totals", err)
+	}
+
+	return router.SendJSONResponse(resp, 200, w, r)
 }
@@ -1,7 +1,7 @@
 package web
 
 import (
-	"log"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -12,24 +12,25 @@ import (
 )
 
 type (
-	hostStatusResponse struct {
+	// HostStatusResponse the host's current status and alerts
+	HostStatusResponse struct {
 		router.APIResponse
 		Status types.HostStatus  `json:"status"`
 		Alerts []types.HostAlert `json:"alerts"`
 	}
 )
 
-func handleGetHostStatus(w http.ResponseWriter, r *router.APIRequest) {
+// getHostStatus returns the host's current status merged with the last
+// metadata and its usage over the last 30 days
+func getHostStatus() (types.HostStatus, error) {
 	meta, err := persist.GetLastMetadata()
 	if err != nil {
-		log.Println(err)
-		router.HandleError("unable to retrieve metadata", 500, w, r)
+		return types.HostStatus{}, fmt.Errorf("get metadata: %w", err)
 	}
 
 	usage, err := persist.GetClosestMeta(time.Now().AddDate(0, 0, -30))
 	if err != nil {
-		log.Println(err)
-		router.HandleError("unable to retrieve past usage", 500, w, r)
+		return types.HostStatus{}, fmt.Errorf("get past usage: %w", err)
 	}
 
 	status := cache.GetHostStatus()
@@ -44,10 +45,23 @@ func handleGetHostStatus(w http.ResponseWriter, r *router.APIRequest) {
 	status.EarnedRevenue = meta.EarnedRevenue
 	status.PotentialRevenue = meta.PotentialRevenue
 	status.BurntCollateral = meta.BurntCollateral
+	status.EarnedBreakdown = meta.EarnedBreakdown
+	status.PotentialBreakdown = meta.PotentialBreakdown
 	status.FirstSeen = meta.FirstSeen
 	status.StorageDelta = int64(status.UsedStorage) - int64(usage.UsedStorage)
+	status.DaysUntilFull = cache.GetCapacityPlan().Host.DaysUntilFull
+	status.Online = cache.GetConnectivity().Online()
 
-	router.SendJSONResponse(hostStatusResponse{
+	return status, nil
+}
+
+func handleGetHostStatus(w http.ResponseWriter, r *router.APIRequest) error {
+	status, err := getHostStatus()
+	if err != nil {
+		return router.StorageError("unable to retrieve status", err)
+	}
+
+	return router.SendJSONResponse(HostStatusResponse{
 		APIResponse: router.APIResponse{
 			Type: "success",
 		},
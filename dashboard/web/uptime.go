@@ -0,0 +1,112 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/siacentral/sia-host-dashboard/dashboard/persist"
+	"github.com/siacentral/sia-host-dashboard/dashboard/types"
+	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
+)
+
+// maxCheckGap is the longest a single connectivity check is considered
+// representative for. Longer gaps, such as when the dashboard was not running,
+// are excluded from the uptime calculation.
+const maxCheckGap = 30 * time.Minute
+
+type (
+	// HostUptimeResponse the host's uptime, connectivity checks, and outages
+	HostUptimeResponse struct {
+		HostResponse
+		Uptime    float64        `json:"uptime"`
+		Monitored uint64         `json:"monitored"`
+		Checks    int            `json:"checks"`
+		Outages   []types.Outage `json:"outages"`
+	}
+)
+
+// calcUptime returns the percentage of the monitored time the host was online,
+// the monitored time, and the intervals the host was offline
+func calcUptime(checks []types.ConnectivityCheck, end time.Time) (uptime float64, monitored time.Duration, outages []types.Outage) {
+	var online time.Duration
+	var outage *types.Outage
+
+	for i, check := range checks {
+		periodEnd := end
+		if i < len(checks)-1 {
+			periodEnd = checks[i+1].Timestamp
+		}
+
+		gap := periodEnd.Sub(check.Timestamp) > maxCheckGap
+		if gap {
+			periodEnd = check.Timestamp.Add(maxCheckGap)
+		}
+
+		monitored += periodEnd.Sub(check.Timestamp)
+
+		if check.Online() {
+			online += periodEnd.Sub(check.Timestamp)
+			outage = nil
+			continue
+		}
+
+		if outage == nil {
+			outages = append(outages, types.Outage{
+				Start: check.Timestamp,
+			})
+			outage = &outages[len(outages)-1]
+		}
+
+		outage.End = periodEnd
+
+		if gap {
+			outage = nil
+		}
+	}
+
+	if monitored > 0 {
+		uptime = float64(online) / float64(monitored) * 100
+	}
+
+	return
+}
+
+func handleGetUptime(w http.ResponseWriter, r *router.APIRequest) error {
+	current := time.Now().UTC()
+	timestamps := parseTimeParams(r, "start", "end")
+	start, end := timestamps[0], timestamps[1]
+
+	if end.IsZero() || end.After(current) {
+		end = current
+	}
+
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	if end.Before(start) {
+		return router.InvalidParameter("end must be after start")
+	}
+
+	checks, err := persist.GetConnectivityChecks(start, end)
+	if err != nil {
+		return router.StorageError("unable to retrieve connectivity checks", err)
+	}
+
+	uptime, monitored, outages := calcUptime(checks, end)
+
+	return router.SendJSONResponse(HostUptimeResponse{
+		HostResponse: HostResponse{
+			APIResponse: router.APIResponse{
+				Message: "successfully retrieved uptime",
+				Type:    "success",
+			},
+			Start: start,
+			End:   end,
+		},
+		Uptime:    uptime,
+		Monitored: uint64(monitored.Seconds()),
+		Checks:    len(checks),
+		Outages:   outages,
+	}, 200, w, r)
+}
@@ -3,26 +3,45 @@ package web
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/siacentral/sia-host-dashboard/dashboard/web/router"
 )
 
+const (
+	// shareRateLimit the number of requests allowed per minute for each IP
+	// address on the public share endpoints
+	shareRateLimit = 30
+)
+
 var (
-	r *router.APIRouter
+	apiRouter *router.APIRouter
+	// started the time the API started, the period the API stats cover
+	started time.Time
 )
 
+// NewRouter creates a router serving the dashboard's API endpoints and the
+// public share pages. The API stats are served from the last router created.
+func NewRouter(opts router.APIOptions) *router.APIRouter {
+	r := router.NewRouter(endpoints, opts)
+	r.AddGroup("/share", shareEndpoints, router.NewRateLimit(shareRateLimit, time.Minute))
+
+	started = time.Now()
+	apiRouter = r
+
+	return r
+}
+
 //Start starts the api router and listens on the specified address
 func Start(opts router.APIOptions) error {
-	r = router.NewRouter(endpoints, opts)
-
-	return r.ListenAndServe()
+	return NewRouter(opts).ListenAndServe()
 }
 
 //Shutdown attempts to gracefully shutdown the started API router
 func Shutdown(ctx context.Context) error {
-	if r == nil {
+	if apiRouter == nil {
 		return errors.New("server not started")
 	}
 
-	return r.Shutdown(ctx)
+	return apiRouter.Shutdown(ctx)
 }
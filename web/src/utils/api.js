@@ -1,3 +1,7 @@
+// the dashboard injects its base path into index.html, the build's API url is
+// used when running the development server
+const apiBaseURL = typeof window.DASHBOARD_BASE_PATH === 'string' ? window.DASHBOARD_BASE_PATH : process.env.VUE_APP_API_BASE_URL;
+
 async function sendJSONRequest(url, method, data) {
 	let headers = {};
 
@@ -35,7 +39,7 @@ export async function getSnapshots(end) {
 	if (!end)
 		end = new Date();
 
-	const resp = await sendJSONRequest(`${process.env.VUE_APP_API_BASE_URL}/api/snapshots?end=${Math.round(end.getTime() / 1000)}`, 'GET', null, true);
+	const resp = await sendJSONRequest(`${apiBaseURL}/api/v1/snapshots?end=${Math.round(end.getTime() / 1000)}`, 'GET', null, true);
 
 	if (resp.statusCode !== 200)
 		throw new Error(resp.body.message);
@@ -47,7 +51,7 @@ export async function getSnapshots(end) {
 }
 
 export async function getStatus() {
-	const resp = await sendJSONRequest(`${process.env.VUE_APP_API_BASE_URL}/api/status`, 'GET', null, true);
+	const resp = await sendJSONRequest(`${apiBaseURL}/api/v1/status`, 'GET', null, true);
 
 	if (resp.statusCode !== 200)
 		throw new Error(resp.body.message);
@@ -59,10 +63,24 @@ export async function getTotals(end) {
 	if (!end)
 		end = new Date();
 
-	const resp = await sendJSONRequest(`${process.env.VUE_APP_API_BASE_URL}/api/totals?date=${Math.round(end.getTime() / 1000)}`, 'GET', null, true);
+	const resp = await sendJSONRequest(`${apiBaseURL}/api/v1/totals?date=${Math.round(end.getTime() / 1000)}`, 'GET', null, true);
 
 	if (resp.statusCode !== 200)
 		throw new Error(resp.body.message);
 
 	return resp.body;
-}
\ No newline at end of file
+}
+export async function getMetrics(end) {
+	if (!end)
+		end = new Date();
+
+	const resp = await sendJSONRequest(`${apiBaseURL}/api/v1/metrics?end=${Math.round(end.getTime() / 1000)}`, 'GET', null, true);
+
+	if (resp.statusCode !== 200)
+		throw new Error(resp.body.message);
+
+	if (!Array.isArray(resp.body.metrics))
+		return [];
+
+	return resp.body.metrics;
+}
@@ -25,7 +25,9 @@ module.exports = {
 		name: 'SiaCentral'
 	},
 
-	publicPath: undefined,
+	// assets are loaded relative to index.html so the dashboard can be served
+	// under a base path
+	publicPath: './',
 	outputDir: undefined,
 	assetsDir: undefined,
 	runtimeCompiler: undefined,